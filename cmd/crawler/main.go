@@ -2,17 +2,149 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/letieu/idea-extractor/config"
 	"github.com/letieu/idea-extractor/internal/crawl"
+	"github.com/letieu/idea-extractor/internal/database"
 )
 
 func main() {
-	ctx := context.Background()
-	crawler, err := crawl.New(ctx)
+	backfill := flag.String("backfill", "", "subreddit to backfill instead of crawling the configured ones")
+	until := flag.String("until", "", "stop backfilling at posts older than this date (YYYY-MM-DD)")
+	untilID := flag.String("until-id", "", "stop backfilling at this post ID")
+	source := flag.String("source", "", "only crawl this source, e.g. reddit")
+	channels := flag.String("channels", "", "comma-separated channels of -source to crawl, e.g. subreddits")
+	dryRun := flag.Bool("dry-run", false, "analyze without writing to the database, printing the results as JSON lines")
+	out := flag.String("out", "", "file to write the dry run results to instead of stdout")
+	skipExisting := flag.Bool("skip-existing", false, "in a dry run, skip items already in the database, which is only read")
+	maxLLMCalls := flag.Int("max-llm-calls", 0, "in a dry run, analyze at most this many items, 0 for no limit")
+	flag.Parse()
+
+	var untilTime time.Time
+	if *until != "" {
+		var err error
+		untilTime, err = time.Parse(time.DateOnly, *until)
+		if err != nil {
+			log.Fatalf("Invalid -until date: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var crawler *crawl.Crawler
+	var store *crawl.MemoryStore
+	var err error
+	if *dryRun {
+		crawler, store, err = newDryRun(ctx, *out, *skipExisting, *maxLLMCalls)
+	} else {
+		crawler, err = crawl.New(ctx)
+	}
 	if err != nil {
 		log.Fatalf("Error: %v", err)
 	}
+	defer crawler.Close()
+	if store != nil {
+		defer logDryRun(store)
+	}
+
+	run := func(ctx context.Context) error {
+		switch {
+		case *backfill != "":
+			return crawler.Backfill(ctx, *backfill, untilTime, *untilID)
+		case *source != "":
+			var names []string
+			if *channels != "" {
+				names = strings.Split(*channels, ",")
+			}
+			return crawler.CrawlChannels(ctx, *source, names)
+		default:
+			return crawler.CrawlAll(ctx)
+		}
+	}
 
-	crawler.CrawlAll(ctx)
+	// Dry runs write nothing, they can run next to a real crawl
+	if *dryRun {
+		err = run(ctx)
+	} else {
+		err = withCrawlLock(ctx, run)
+	}
+	if errors.Is(err, database.ErrLocked) {
+		log.Printf("Another crawl is running, try again once it is done")
+		return
+	}
+	if err != nil {
+		if *backfill != "" {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		log.Printf("Crawl stopped: %v", err)
+	}
+}
+
+// withCrawlLock runs fn holding the crawl lock, which the daemon takes too.
+func withCrawlLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.WithLock(ctx, database.LockCrawl, cfg.Daemon.LockTTL, fn)
+}
+
+// newDryRun creates a crawler that writes its results to out, or stdout.
+// The database is only opened to skip known items when skipExisting is set.
+func newDryRun(ctx context.Context, out string, skipExisting bool, maxLLMCalls int) (*crawl.Crawler, *crawl.MemoryStore, error) {
+	opts := crawl.DryRunOptions{Output: os.Stdout, MaxLLMCalls: maxLLMCalls}
+
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Closed when the process exits
+		opts.Output = f
+	}
+
+	var known crawl.ItemChecker
+	if skipExisting {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		db, err := database.NewDB(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Only asked whether items exist, the dry run is kept in memory
+		known = db
+	}
+	opts.Store = crawl.NewMemoryStore(known)
+
+	crawler, err := crawl.NewDryRun(ctx, opts)
+	return crawler, opts.Store, err
+}
+
+// logDryRun sums up what a dry run would have stored.
+func logDryRun(store *crawl.MemoryStore) {
+	counts := map[string]int{}
+	items := store.SourceItems()
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	log.Printf("Dry run: %d items in %d runs, %d analyzed, %d meta, %d empty, %d failed, %d abandoned",
+		len(items), len(store.CrawlRuns()), counts[database.StatusAnalyzed], counts[database.StatusMeta],
+		counts[database.StatusEmpty], counts[database.StatusFailed], counts[database.StatusAbandoned])
 }
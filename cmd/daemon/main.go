@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/crawl"
+	"github.com/letieu/idea-extractor/internal/daemon"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/group"
+)
+
+// daemon replaces the crawler and grouper cron jobs: it crawls on the
+// daemon.schedules and groups after every crawl. On SIGTERM it lets the
+// running crawl store what it analyzed and exits, a second signal kills it.
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+	defer db.Close()
+
+	crawler, err := crawl.New(ctx)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	defer crawler.Close()
+
+	grouper, err := group.New()
+	if err != nil {
+		log.Fatalf("fail to init grouper %v", err)
+	}
+	defer grouper.Close()
+
+	d, err := daemon.New(cfg, db, crawler, grouper)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	var server *http.Server
+	if cfg.Daemon.Listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/healthz", d)
+		server = &http.Server{Addr: cfg.Daemon.Listen, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Health endpoint stopped: %v", err)
+			}
+		}()
+		log.Printf("Health endpoint listening on %s/healthz", cfg.Daemon.Listen)
+	}
+
+	go func() {
+		<-ctx.Done()
+		log.Printf("Shutting down, waiting for the running job to store its results...")
+		stop()
+	}()
+
+	d.Run(ctx)
+
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+	log.Printf("Stopped")
+}
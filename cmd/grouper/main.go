@@ -2,18 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/database"
 	"github.com/letieu/idea-extractor/internal/group"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+	defer db.Close()
+
 	grouper, err := group.New()
 	if err != nil {
 		log.Fatalf("fail to init grouper %v", err)
 	}
+	defer grouper.Close()
 
-	err = grouper.ProcessSourceItems(context.Background())
+	// The group lock keeps this from running next to the daemon's grouping
+	err = db.WithLock(context.Background(), database.LockGroup, cfg.Daemon.LockTTL, grouper.ProcessSourceItems)
+	if errors.Is(err, database.ErrLocked) {
+		log.Printf("Another grouping is running, try again once it is done")
+		return
+	}
 	if err != nil {
 		log.Fatalf("fail to run group %v", err)
 	}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/crawl"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+func main() {
+	format := flag.String("format", "", "input format: discord, slack, telegram, reviews (CSV or JSON dumps), web (page URLs) or email (mbox files or Maildirs)")
+	maxRating := flag.Float64("max-rating", 3, "only import reviews rated this or lower, 0 imports all")
+	urlList := flag.String("urls", "", "file with one page URL per line, for -format web")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -format <format> <export or URL>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if *urlList != "" {
+		urls, err := readURLList(*urlList)
+		if err != nil {
+			log.Fatalf("Failed to read URL list: %v", err)
+		}
+		args = append(args, urls...)
+	}
+
+	if *format == "" || len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	var src crawl.Source
+	switch *format {
+	case "reviews":
+		src, err = crawl.NewReviewSource(args, *maxRating, db)
+	case "web":
+		src, err = crawl.NewWebSource(args, db)
+	case "email":
+		src, err = crawl.NewMailSource(args)
+	default:
+		src, err = crawl.NewChatSource(*format, args)
+	}
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	crawler, err := crawl.New(ctx)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	defer crawler.Close()
+
+	// Imports take the crawl lock so they do not race the daemon on the
+	// same items
+	err = db.WithLock(ctx, database.LockCrawl, cfg.Daemon.LockTTL, func(ctx context.Context) error {
+		return crawler.CrawlSource(ctx, src)
+	})
+	if errors.Is(err, database.ErrLocked) {
+		log.Printf("Another crawl is running, try again once it is done")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+}
+
+// readURLList reads one URL per line, skipping blank lines and # comments.
+func readURLList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
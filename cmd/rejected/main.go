@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// rejected lists the items the analyzer rejected as meta posts or found
+// empty, and the ones given up after failing too often, so analysts can
+// check the decisions and send wrong ones back for analysis.
+func main() {
+	status := flag.String("status", "meta,empty,abandoned", "comma-separated statuses to list")
+	source := flag.String("source", "", "only list items from this source, e.g. reddit")
+	limit := flag.Int("limit", 50, "maximum number of items to list")
+	verbose := flag.Bool("v", false, "print the analysis result or last error of every item")
+	requeue := flag.Int("requeue", 0, "ID of an item to analyze again on the next retry run")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+	defer db.Close()
+
+	if *requeue != 0 {
+		if err := db.RequeueSourceItem(*requeue); err != nil {
+			log.Fatalf("Failed to requeue source item: %v", err)
+		}
+		log.Printf("Source item %d will be analyzed again on the next retry run", *requeue)
+		return
+	}
+
+	items, err := db.ListSourceItemsByStatus(strings.Split(*status, ","), *source, *limit)
+	if err != nil {
+		log.Fatalf("Failed to list source items: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tSOURCE\tCHANNEL\tCREATED\tTITLE\tURL")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.ID, item.Status, item.Source, item.Channel,
+			item.CreatedAt.Format("2006-01-02 15:04"), truncate(item.Title, 60), item.URL)
+		if *verbose {
+			reason := item.AnalysisResult
+			if item.Status == database.StatusAbandoned {
+				reason = fmt.Sprintf("%d attempts, last error: %s", item.Attempts, item.LastError)
+			}
+			fmt.Fprintf(w, "\t%s\n", reason)
+		}
+	}
+	w.Flush()
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
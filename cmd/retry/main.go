@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/crawl"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+func main() {
+	limit := flag.Int("limit", 100, "maximum number of failed items to retry")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	crawler, err := crawl.New(ctx)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	defer crawler.Close()
+
+	// The crawl lock keeps retries from analyzing items a crawl is saving
+	err = db.WithLock(ctx, database.LockCrawl, cfg.Daemon.LockTTL, func(ctx context.Context) error {
+		return crawler.RetryFailed(ctx, *limit)
+	})
+	if errors.Is(err, database.ErrLocked) {
+		log.Printf("Another crawl is running, try again once it is done")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Retry failed: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// runs shows the latest crawl runs and, per source channel, how many items
+// each week brought, so channels that stopped producing anything stand out.
+func main() {
+	source := flag.String("source", "", "only show runs of this source, e.g. reddit")
+	channel := flag.String("channel", "", "only show runs of this channel, e.g. a subreddit")
+	limit := flag.Int("limit", 20, "number of recent runs to show")
+	weeks := flag.Int("weeks", 4, "number of weeks of trends to show, 0 to skip them")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+	defer db.Close()
+
+	runs, err := db.ListCrawlRuns(*source, *channel, *limit)
+	if err != nil {
+		log.Fatalf("Failed to list crawl runs: %v", err)
+	}
+	printRuns(runs)
+
+	if *weeks <= 0 {
+		return
+	}
+	trends, err := db.GetCrawlRunTrends(time.Now().AddDate(0, 0, -7*(*weeks)))
+	if err != nil {
+		log.Fatalf("Failed to get crawl run trends: %v", err)
+	}
+	fmt.Println()
+	printTrends(trends, *source, *channel)
+}
+
+func printRuns(runs []*database.CrawlRun) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tSOURCE\tCHANNEL\tFETCHED\tEXISTING\tFILTERED\tANALYZED\tMETA\tEMPTY\tFAILED\tTOKENS\tAVG LATENCY\tERROR")
+	for _, run := range runs {
+		duration := "running"
+		if !run.FinishedAt.IsZero() {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+		avgLatency := "-"
+		if run.LLMCalls > 0 {
+			avgLatency = (run.LLMLatency / time.Duration(run.LLMCalls)).Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			run.ID, run.StartedAt.Local().Format("2006-01-02 15:04"), duration, run.Source, run.Channel,
+			run.Fetched, run.SkippedExisting, run.SkippedFiltered, run.Analyzed, run.Meta, run.Empty, run.Failed,
+			run.PromptTokens+run.CompletionTokens, avgLatency, run.Error)
+	}
+	w.Flush()
+}
+
+// printTrends shows one line per channel with the analyzed and fetched
+// counts of every week. Channels whose latest week analyzed nothing are
+// flagged.
+func printTrends(trends []*database.CrawlRunTrend, source, channel string) {
+	type channelKey struct{ source, channel string }
+	var keys []channelKey
+	byChannel := map[channelKey]map[string]*database.CrawlRunTrend{}
+	weekSet := map[string]bool{}
+	for _, t := range trends {
+		if (source != "" && t.Source != source) || (channel != "" && !strings.EqualFold(t.Channel, channel)) {
+			continue
+		}
+		key := channelKey{t.Source, t.Channel}
+		if byChannel[key] == nil {
+			byChannel[key] = map[string]*database.CrawlRunTrend{}
+			keys = append(keys, key)
+		}
+		byChannel[key][t.Week] = t
+		weekSet[t.Week] = true
+	}
+
+	weeks := make([]string, 0, len(weekSet))
+	for week := range weekSet {
+		weeks = append(weeks, week)
+	}
+	sort.Strings(weeks)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "SOURCE\tCHANNEL")
+	for _, week := range weeks {
+		fmt.Fprintf(w, "\t%s", week)
+	}
+	fmt.Fprintln(w, "\tTOKENS/ANALYZED\t")
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s", key.source, key.channel)
+
+		var latest *database.CrawlRunTrend
+		analyzed, tokens := 0, 0
+		for _, week := range weeks {
+			t, ok := byChannel[key][week]
+			if !ok {
+				fmt.Fprint(w, "\t-")
+				continue
+			}
+			// Analyzed out of fetched
+			fmt.Fprintf(w, "\t%d/%d", t.Analyzed, t.Fetched)
+			latest = t
+			analyzed += t.Analyzed
+			tokens += t.Tokens
+		}
+
+		perItem := "-"
+		if analyzed > 0 {
+			perItem = fmt.Sprintf("%d", tokens/analyzed)
+		}
+		note := ""
+		if latest != nil && latest.Analyzed == 0 {
+			note = "no new items analyzed in its latest week"
+		}
+		fmt.Fprintf(w, "\t%s\t%s\n", perItem, note)
+	}
+	w.Flush()
+}
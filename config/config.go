@@ -2,31 +2,157 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Reddit struct {
+		Mode         string // oauth or anonymous
 		ClientID     string
 		ClientSecret string
+		UserAgent    string
 	}
 	Mistral struct {
-		APIKey string
-		Model  string
+		APIKey            string
+		Model             string
+		RequestsPerMinute int // 0 for no limit
 	}
 	Database struct {
 		Url   string
 		Token string
 	}
+	HackerNews struct {
+		BaseURL     string
+		Lists       []HackerNewsList
+		PostLimit   int
+		MaxComments int // 0 means no limit
+	}
+	Feeds  []Feed
+	GitHub struct {
+		Token       string
+		BaseURL     string
+		Repos       []GitHubRepo
+		MaxComments int
+	}
+	StackExchange struct {
+		Key        string
+		BaseURL    string
+		Sites      []StackExchangeSite
+		MaxAnswers int
+	}
+	Mastodon struct {
+		Timelines  []MastodonTimeline
+		MaxReplies int
+	}
 	Crawler struct {
-		Subreddits      []string
+		Sources         []string
+		Subreddits      []Subreddit
 		PostLimit       int
 		RateLimitSecs   int
 		SharingKeywords []string
+		SkipStickied    bool
+		SkipNSFW        bool
+		MinComments     int
+		Concurrency     int // Items analyzed at the same time
+
+		// Failed analyses are retried with an exponential backoff starting
+		// at RetryBaseDelay, and given up after RetryMaxAttempts
+		RetryMaxAttempts int
+		RetryBaseDelay   time.Duration
+	}
+	Daemon struct {
+		Listen    string        // Address of the health endpoint, empty to disable it
+		LockTTL   time.Duration // Locks held by a process that died expire after this
+		Schedules []Schedule
 	}
 }
 
+// Subreddit is a crawler.subreddits entry. Entries can be written as a plain
+// subreddit name or as an object that picks the listing to crawl.
+type Subreddit struct {
+	Name    string
+	Listing string // new, hot, top, rising or search
+	Time    string // Time window for top and search: hour, day, week, month, year or all
+	Query   string // Search query, required for the search listing
+
+	// Rules applied to posts before they are sent to the analyzer
+	Limit      int              // Posts to fetch per crawl, 0 uses crawler.post_limit
+	MinScore   int              // Minimum post score
+	FlairAllow []string         // Only keep posts with one of these flairs
+	FlairDeny  []string         // Drop posts with one of these flairs
+	Include    []*regexp.Regexp // Title or body must match one of these
+	Exclude    []*regexp.Regexp // Title or body must match none of these
+	Focus      string           // Analyzer hint: products or problems
+}
+
+// HackerNewsList is a hackernews.lists entry, a search on the Algolia HN
+// API such as the latest Ask HN stories.
+type HackerNewsList struct {
+	Name  string
+	Tags  string // Algolia tags, e.g. ask_hn or show_hn
+	Query string // Full-text query, optional
+	Focus string // Analyzer hint: products or problems
+}
+
+// Feed is a feeds entry, an RSS or Atom feed to crawl.
+type Feed struct {
+	Name  string
+	URL   string
+	Limit int    // Newest entries to look at per crawl, 0 for the whole feed
+	Focus string // Analyzer hint: products or problems
+}
+
+// GitHubRepo is a github.repos entry. Issues with any of the labels are
+// crawled, and optionally the repository discussions.
+type GitHubRepo struct {
+	Name               string // owner/name
+	Labels             []string
+	Discussions        bool
+	DiscussionCategory string // Only crawl discussions in this category, e.g. Ideas
+	Limit              int    // Issues per label and discussions per crawl
+	Focus              string // Analyzer hint: products or problems
+}
+
+// StackExchangeSite is a stackexchange.sites entry, a question listing on
+// one Stack Exchange site such as softwarerecs.
+type StackExchangeSite struct {
+	Name    string
+	Site    string   // API site parameter, e.g. softwarerecs or webapps
+	Listing string   // new, unanswered or no_answers
+	Tagged  []string // Questions must have all of these tags
+	Query   string   // Full-text search, optional
+	Limit   int      // Questions per crawl
+	Focus   string   // Analyzer hint: products or problems
+}
+
+// MastodonTimeline is a mastodon.timelines entry, the hashtag timeline or
+// the statuses of one account on a Mastodon instance.
+type MastodonTimeline struct {
+	Name     string
+	Instance string // Host name such as mastodon.social, or a base URL
+	Token    string // Access token for instances that require one
+	Hashtag  string // Without the leading #
+	Account  string // acct, e.g. alice@mastodon.social
+	Limit    int    // Statuses per crawl
+	Focus    string // Analyzer hint: products or problems
+}
+
+// Schedule is a daemon.schedules entry, when to crawl a source or some of
+// its channels. Items are grouped after every crawl.
+type Schedule struct {
+	Name     string
+	Cron     string   // Five-field cron expression or a descriptor such as @hourly
+	Source   string   // A source from crawler.sources, e.g. reddit
+	Channels []string // Subreddits, lists or feeds of the source, all of them when empty
+}
+
 func Load() (*Config, error) {
 	v := viper.New()
 
@@ -50,22 +176,90 @@ func Load() (*Config, error) {
 	cfg := &Config{}
 
 	// Reddit config
+	cfg.Reddit.Mode = v.GetString("reddit.mode")
 	cfg.Reddit.ClientID = v.GetString("reddit.client_id")
 	cfg.Reddit.ClientSecret = v.GetString("reddit.client_secret")
+	cfg.Reddit.UserAgent = v.GetString("reddit.user_agent")
 
 	// Mistral config
 	cfg.Mistral.APIKey = v.GetString("mistral.api_key")
 	cfg.Mistral.Model = v.GetString("mistral.model")
+	cfg.Mistral.RequestsPerMinute = v.GetInt("mistral.requests_per_minute")
 
 	// Database config
 	cfg.Database.Url = v.GetString("database.url")
 	cfg.Database.Token = v.GetString("database.token")
 
+	// Hacker News config
+	cfg.HackerNews.BaseURL = v.GetString("hackernews.base_url")
+	cfg.HackerNews.PostLimit = v.GetInt("hackernews.post_limit")
+	cfg.HackerNews.MaxComments = v.GetInt("hackernews.max_comments")
+	hnLists, err := parseHackerNewsLists(v.Get("hackernews.lists"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.HackerNews.Lists = hnLists
+
+	// Feeds config
+	feeds, err := parseFeeds(v.Get("feeds"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Feeds = feeds
+
+	// GitHub config
+	cfg.GitHub.Token = v.GetString("github.token")
+	cfg.GitHub.BaseURL = v.GetString("github.base_url")
+	cfg.GitHub.MaxComments = v.GetInt("github.max_comments")
+	repos, err := parseGitHubRepos(v.Get("github.repos"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.GitHub.Repos = repos
+
+	// Stack Exchange config
+	cfg.StackExchange.Key = v.GetString("stackexchange.key")
+	cfg.StackExchange.BaseURL = v.GetString("stackexchange.base_url")
+	cfg.StackExchange.MaxAnswers = v.GetInt("stackexchange.max_answers")
+	sites, err := parseStackExchangeSites(v.Get("stackexchange.sites"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.StackExchange.Sites = sites
+
+	// Mastodon config
+	cfg.Mastodon.MaxReplies = v.GetInt("mastodon.max_replies")
+	timelines, err := parseMastodonTimelines(v.Get("mastodon.timelines"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Mastodon.Timelines = timelines
+
 	// Crawler config
-	cfg.Crawler.Subreddits = v.GetStringSlice("crawler.subreddits")
+	cfg.Crawler.Sources = v.GetStringSlice("crawler.sources")
+	subreddits, err := parseSubreddits(v.Get("crawler.subreddits"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Crawler.Subreddits = subreddits
 	cfg.Crawler.PostLimit = v.GetInt("crawler.post_limit")
 	cfg.Crawler.RateLimitSecs = v.GetInt("crawler.rate_limit_secs")
 	cfg.Crawler.SharingKeywords = v.GetStringSlice("crawler.sharing_keywords")
+	cfg.Crawler.SkipStickied = v.GetBool("crawler.skip_stickied")
+	cfg.Crawler.SkipNSFW = v.GetBool("crawler.skip_nsfw")
+	cfg.Crawler.MinComments = v.GetInt("crawler.min_comments")
+	cfg.Crawler.Concurrency = v.GetInt("crawler.concurrency")
+	cfg.Crawler.RetryMaxAttempts = v.GetInt("crawler.retry_max_attempts")
+	cfg.Crawler.RetryBaseDelay = v.GetDuration("crawler.retry_base_delay")
+
+	// Daemon config
+	cfg.Daemon.Listen = v.GetString("daemon.listen")
+	cfg.Daemon.LockTTL = v.GetDuration("daemon.lock_ttl")
+	schedules, err := parseSchedules(v.Get("daemon.schedules"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Daemon.Schedules = schedules
 
 	// Validate required fields
 	if err := validate(cfg); err != nil {
@@ -76,13 +270,34 @@ func Load() (*Config, error) {
 }
 
 func setDefaults(v *viper.Viper) {
+	// Reddit defaults
+	v.SetDefault("reddit.mode", "oauth")
+
 	// Database defaults
 	v.SetDefault("database.type", "sqlite")
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", "5432")
 	v.SetDefault("database.dbname", "ideas.db")
 
+	// Hacker News defaults
+	v.SetDefault("hackernews.post_limit", 30)
+	v.SetDefault("hackernews.max_comments", 100)
+	v.SetDefault("hackernews.lists", []any{
+		map[string]any{"name": "ask_hn", "tags": "ask_hn"},
+		map[string]any{"name": "show_hn", "tags": "show_hn", "focus": "products"},
+	})
+
+	// GitHub defaults
+	v.SetDefault("github.max_comments", 50)
+
+	// Stack Exchange defaults
+	v.SetDefault("stackexchange.max_answers", 10)
+
+	// Mastodon defaults
+	v.SetDefault("mastodon.max_replies", 50)
+
 	// Crawler defaults
+	v.SetDefault("crawler.sources", []string{"reddit"})
 	v.SetDefault("crawler.subreddits", []string{
 		"SideProject",
 		"Entrepreneur",
@@ -92,6 +307,12 @@ func setDefaults(v *viper.Viper) {
 	})
 	v.SetDefault("crawler.post_limit", 25)
 	v.SetDefault("crawler.rate_limit_secs", 2)
+	v.SetDefault("crawler.skip_stickied", true)
+	v.SetDefault("crawler.skip_nsfw", true)
+	v.SetDefault("crawler.min_comments", 0)
+	v.SetDefault("crawler.concurrency", 4)
+	v.SetDefault("crawler.retry_max_attempts", 5)
+	v.SetDefault("crawler.retry_base_delay", "15m")
 	v.SetDefault("crawler.sharing_keywords", []string{
 		"share what you're building",
 		"share what you are building",
@@ -101,14 +322,24 @@ func setDefaults(v *viper.Viper) {
 		"share your startup",
 		"show your side project",
 	})
+
+	// Daemon defaults
+	v.SetDefault("daemon.listen", ":8090")
+	v.SetDefault("daemon.lock_ttl", "10m")
 }
 
 func validate(cfg *Config) error {
-	if cfg.Reddit.ClientID == "" {
-		return fmt.Errorf("reddit.client_id is required")
-	}
-	if cfg.Reddit.ClientSecret == "" {
-		return fmt.Errorf("reddit.client_secret is required")
+	switch cfg.Reddit.Mode {
+	case "oauth":
+		if cfg.Reddit.ClientID == "" {
+			return fmt.Errorf("reddit.client_id is required")
+		}
+		if cfg.Reddit.ClientSecret == "" {
+			return fmt.Errorf("reddit.client_secret is required")
+		}
+	case "anonymous":
+	default:
+		return fmt.Errorf("reddit.mode must be oauth or anonymous, got %q", cfg.Reddit.Mode)
 	}
 	if cfg.Mistral.APIKey == "" {
 		return fmt.Errorf("mistral.api_key is required")
@@ -116,5 +347,394 @@ func validate(cfg *Config) error {
 	if cfg.Database.Url == "" {
 		return fmt.Errorf("database.url is required")
 	}
+	if cfg.Crawler.Concurrency < 1 {
+		return fmt.Errorf("crawler.concurrency must be at least 1")
+	}
+	for _, list := range cfg.HackerNews.Lists {
+		if err := validateFocus("hackernews.lists", list.Name, list.Focus); err != nil {
+			return err
+		}
+	}
+	for _, feed := range cfg.Feeds {
+		if err := validateFocus("feeds", feed.Name, feed.Focus); err != nil {
+			return err
+		}
+	}
+	for _, repo := range cfg.GitHub.Repos {
+		if repo.Discussions && cfg.GitHub.Token == "" {
+			return fmt.Errorf("github.repos: %s: github.token is required to crawl discussions", repo.Name)
+		}
+		if err := validateFocus("github.repos", repo.Name, repo.Focus); err != nil {
+			return err
+		}
+	}
+	for _, site := range cfg.StackExchange.Sites {
+		switch site.Listing {
+		case "new", "unanswered", "no_answers":
+		default:
+			return fmt.Errorf("stackexchange.sites: %s: unknown listing %q", site.Name, site.Listing)
+		}
+		if err := validateFocus("stackexchange.sites", site.Name, site.Focus); err != nil {
+			return err
+		}
+	}
+	for _, tl := range cfg.Mastodon.Timelines {
+		if err := validateFocus("mastodon.timelines", tl.Name, tl.Focus); err != nil {
+			return err
+		}
+	}
+	if cfg.Daemon.LockTTL < time.Minute {
+		return fmt.Errorf("daemon.lock_ttl must be at least 1m")
+	}
+	for _, sched := range cfg.Daemon.Schedules {
+		if !slices.Contains(cfg.Crawler.Sources, sched.Source) {
+			return fmt.Errorf("daemon.schedules: %s: source %q is not in crawler.sources", sched.Name, sched.Source)
+		}
+	}
+	for _, sub := range cfg.Crawler.Subreddits {
+		switch sub.Listing {
+		case "new", "hot", "top", "rising":
+		case "search":
+			if sub.Query == "" {
+				return fmt.Errorf("crawler.subreddits: %s: query is required for the search listing", sub.Name)
+			}
+		default:
+			return fmt.Errorf("crawler.subreddits: %s: unknown listing %q", sub.Name, sub.Listing)
+		}
+		switch sub.Time {
+		case "", "hour", "day", "week", "month", "year", "all":
+		default:
+			return fmt.Errorf("crawler.subreddits: %s: unknown time %q", sub.Name, sub.Time)
+		}
+		if err := validateFocus("crawler.subreddits", sub.Name, sub.Focus); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// validateFocus checks the analyzer hint of a source list entry, a typo
+// would silently turn it off.
+func validateFocus(key, name, focus string) error {
+	switch focus {
+	case "", "products", "problems":
+		return nil
+	}
+	return fmt.Errorf("%s: %s: focus must be products or problems, got %q", key, name, focus)
+}
+
+// parseSubreddits accepts the raw crawler.subreddits value, a list mixing
+// subreddit names and option objects.
+func parseSubreddits(raw any) ([]Subreddit, error) {
+	var entries []any
+	switch list := raw.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		for _, name := range list {
+			entries = append(entries, name)
+		}
+	case []any:
+		entries = list
+	default:
+		return nil, fmt.Errorf("crawler.subreddits must be a list")
+	}
+
+	subreddits := make([]Subreddit, 0, len(entries))
+	for i, entry := range entries {
+		sub := Subreddit{Listing: "new"}
+		switch e := entry.(type) {
+		case string:
+			sub.Name = e
+		case map[string]any:
+			sub.Name = stringField(e, "name")
+			if listing := stringField(e, "listing"); listing != "" {
+				sub.Listing = listing
+			}
+			sub.Time = stringField(e, "time")
+			sub.Query = stringField(e, "query")
+			sub.Focus = stringField(e, "focus")
+			sub.FlairAllow = stringsField(e, "flair_allow")
+			sub.FlairDeny = stringsField(e, "flair_deny")
+
+			var err error
+			if sub.Limit, err = intField(e, "limit"); err != nil {
+				return nil, fmt.Errorf("crawler.subreddits[%d]: %w", i, err)
+			}
+			if sub.MinScore, err = intField(e, "min_score"); err != nil {
+				return nil, fmt.Errorf("crawler.subreddits[%d]: %w", i, err)
+			}
+			if sub.Include, err = regexpsField(e, "include"); err != nil {
+				return nil, fmt.Errorf("crawler.subreddits[%d]: %w", i, err)
+			}
+			if sub.Exclude, err = regexpsField(e, "exclude"); err != nil {
+				return nil, fmt.Errorf("crawler.subreddits[%d]: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("crawler.subreddits[%d]: expected a name or an object", i)
+		}
+		if sub.Name == "" {
+			return nil, fmt.Errorf("crawler.subreddits[%d]: name is required", i)
+		}
+		subreddits = append(subreddits, sub)
+	}
+	return subreddits, nil
+}
+
+func parseHackerNewsLists(raw any) ([]HackerNewsList, error) {
+	entries, err := objectList("hackernews.lists", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]HackerNewsList, 0, len(entries))
+	for i, e := range entries {
+		list := HackerNewsList{
+			Name:  stringField(e, "name"),
+			Tags:  stringField(e, "tags"),
+			Query: stringField(e, "query"),
+			Focus: stringField(e, "focus"),
+		}
+		if list.Name == "" {
+			return nil, fmt.Errorf("hackernews.lists[%d]: name is required", i)
+		}
+		if list.Tags == "" && list.Query == "" {
+			return nil, fmt.Errorf("hackernews.lists[%d]: tags or query is required", i)
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func parseFeeds(raw any) ([]Feed, error) {
+	entries, err := objectList("feeds", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds := make([]Feed, 0, len(entries))
+	for i, e := range entries {
+		feed := Feed{
+			Name:  stringField(e, "name"),
+			URL:   stringField(e, "url"),
+			Focus: stringField(e, "focus"),
+		}
+		if feed.Limit, err = intField(e, "limit"); err != nil {
+			return nil, fmt.Errorf("feeds[%d]: %w", i, err)
+		}
+		if feed.URL == "" {
+			return nil, fmt.Errorf("feeds[%d]: url is required", i)
+		}
+		if feed.Name == "" {
+			feed.Name = feed.URL
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, nil
+}
+
+func parseGitHubRepos(raw any) ([]GitHubRepo, error) {
+	entries, err := objectList("github.repos", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]GitHubRepo, 0, len(entries))
+	for i, e := range entries {
+		repo := GitHubRepo{
+			Name:               stringField(e, "name"),
+			Labels:             stringsField(e, "labels"),
+			Discussions:        stringField(e, "discussions") == "true",
+			DiscussionCategory: stringField(e, "discussion_category"),
+			Focus:              stringField(e, "focus"),
+		}
+		if repo.Limit, err = intField(e, "limit"); err != nil {
+			return nil, fmt.Errorf("github.repos[%d]: %w", i, err)
+		}
+		if repo.Name == "" {
+			return nil, fmt.Errorf("github.repos[%d]: name is required", i)
+		}
+		if repo.Limit == 0 {
+			repo.Limit = 30
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+func parseStackExchangeSites(raw any) ([]StackExchangeSite, error) {
+	entries, err := objectList("stackexchange.sites", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]StackExchangeSite, 0, len(entries))
+	for i, e := range entries {
+		site := StackExchangeSite{
+			Name:    stringField(e, "name"),
+			Site:    stringField(e, "site"),
+			Listing: stringField(e, "listing"),
+			Tagged:  stringsField(e, "tagged"),
+			Query:   stringField(e, "query"),
+			Focus:   stringField(e, "focus"),
+		}
+		if site.Limit, err = intField(e, "limit"); err != nil {
+			return nil, fmt.Errorf("stackexchange.sites[%d]: %w", i, err)
+		}
+		if site.Site == "" {
+			return nil, fmt.Errorf("stackexchange.sites[%d]: site is required", i)
+		}
+		if site.Name == "" {
+			site.Name = site.Site
+		}
+		if site.Listing == "" {
+			site.Listing = "new"
+		}
+		if site.Limit == 0 {
+			site.Limit = 30
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+func parseMastodonTimelines(raw any) ([]MastodonTimeline, error) {
+	entries, err := objectList("mastodon.timelines", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines := make([]MastodonTimeline, 0, len(entries))
+	for i, e := range entries {
+		tl := MastodonTimeline{
+			Name:     stringField(e, "name"),
+			Instance: stringField(e, "instance"),
+			Token:    stringField(e, "token"),
+			Hashtag:  strings.TrimPrefix(stringField(e, "hashtag"), "#"),
+			Account:  strings.TrimPrefix(stringField(e, "account"), "@"),
+			Focus:    stringField(e, "focus"),
+		}
+		if tl.Limit, err = intField(e, "limit"); err != nil {
+			return nil, fmt.Errorf("mastodon.timelines[%d]: %w", i, err)
+		}
+		if tl.Instance == "" {
+			return nil, fmt.Errorf("mastodon.timelines[%d]: instance is required", i)
+		}
+		if (tl.Hashtag == "") == (tl.Account == "") {
+			return nil, fmt.Errorf("mastodon.timelines[%d]: set either hashtag or account", i)
+		}
+		if tl.Name == "" {
+			if tl.Hashtag != "" {
+				tl.Name = "#" + tl.Hashtag + "@" + tl.Instance
+			} else {
+				tl.Name = "@" + tl.Account
+			}
+		}
+		if tl.Limit == 0 {
+			tl.Limit = 40
+		}
+		timelines = append(timelines, tl)
+	}
+	return timelines, nil
+}
+
+func parseSchedules(raw any) ([]Schedule, error) {
+	entries, err := objectList("daemon.schedules", raw)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]Schedule, 0, len(entries))
+	for i, e := range entries {
+		sched := Schedule{
+			Name:     stringField(e, "name"),
+			Cron:     stringField(e, "cron"),
+			Source:   stringField(e, "source"),
+			Channels: stringsField(e, "channels"),
+		}
+		if sched.Source == "" {
+			return nil, fmt.Errorf("daemon.schedules[%d]: source is required", i)
+		}
+		if _, err := cron.ParseStandard(sched.Cron); err != nil {
+			return nil, fmt.Errorf("daemon.schedules[%d]: invalid cron %q: %w", i, sched.Cron, err)
+		}
+		if sched.Name == "" {
+			sched.Name = sched.Source
+			if len(sched.Channels) > 0 {
+				sched.Name += ":" + strings.Join(sched.Channels, ",")
+			}
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, nil
+}
+
+// objectList reads a config list whose entries are all objects.
+func objectList(key string, raw any) ([]map[string]any, error) {
+	switch list := raw.(type) {
+	case nil:
+		return nil, nil
+	case []map[string]any:
+		return list, nil
+	case []any:
+		entries := make([]map[string]any, 0, len(list))
+		for i, entry := range list {
+			m, ok := entry.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s[%d]: expected an object", key, i)
+			}
+			entries = append(entries, m)
+		}
+		return entries, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+}
+
+func stringField(m map[string]any, key string) string {
+	if v, ok := m[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// stringsField reads a list of strings, a single string counts as a list
+// of one.
+func stringsField(m map[string]any, key string) []string {
+	switch v := m[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func intField(m map[string]any, key string) (int, error) {
+	s := stringField(m, key)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	return n, nil
+}
+
+func regexpsField(m map[string]any, key string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range stringsField(m, key) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
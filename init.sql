@@ -1,8 +1,14 @@
+-- Recreates the whole schema, wiping every table. Existing databases are
+-- upgraded in place when the commands connect, see internal/database/migrate.go.
+
 -- Drop tables if they exist (order matters)
 DROP TABLE IF EXISTS problem_idea;
 DROP TABLE IF EXISTS problem_product;
 DROP TABLE IF EXISTS idea_product;
 DROP TABLE IF EXISTS source_items;
+DROP TABLE IF EXISTS crawl_runs;
+DROP TABLE IF EXISTS imported_reviews;
+DROP TABLE IF EXISTS locks;
 DROP TABLE IF EXISTS problem_categories;
 DROP TABLE IF EXISTS idea_categories;
 DROP TABLE IF EXISTS product_categories;
@@ -101,6 +107,7 @@ CREATE TABLE product_categories (
 CREATE TABLE source_items (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     source TEXT NOT NULL,
+    channel TEXT,
     source_item_id TEXT NOT NULL,
     title TEXT NOT NULL,
     content TEXT NOT NULL,
@@ -110,6 +117,24 @@ CREATE TABLE source_items (
     analysis_result TEXT,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     source_created_at DATETIME,
+    parent_item_id TEXT,
+
+    num_comments INTEGER DEFAULT 0,
+    upvote_ratio REAL,
+    flair TEXT,
+    nsfw BOOLEAN DEFAULT 0,
+    is_self BOOLEAN DEFAULT 1,
+    stickied BOOLEAN DEFAULT 0,
+    edited_at DATETIME,
+    external_url TEXT,
+    subreddit_subscribers INTEGER,
+
+    status TEXT NOT NULL DEFAULT 'analyzed',
+    analysis_mode TEXT,
+    analysis_focus TEXT,
+    attempts INTEGER DEFAULT 0,
+    last_error TEXT,
+    next_retry_at DATETIME,
 
     problem_id INTEGER,
     idea_id INTEGER,
@@ -120,6 +145,58 @@ CREATE TABLE source_items (
     FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL
 );
 
+CREATE INDEX idx_source_items_retry ON source_items (status, next_retry_at);
+
+-- ======================
+-- Imported reviews
+-- ======================
+-- Reviews already stored in a batch, so importing a dump again only
+-- analyzes the reviews it gained
+CREATE TABLE imported_reviews (
+    product TEXT NOT NULL COLLATE NOCASE,
+    review_id TEXT NOT NULL,
+    source_item_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product, review_id)
+);
+
+-- ======================
+-- Crawl runs
+-- ======================
+CREATE TABLE crawl_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL,
+    channel TEXT,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+
+    fetched INTEGER DEFAULT 0,
+    skipped_existing INTEGER DEFAULT 0,
+    skipped_filtered INTEGER DEFAULT 0,
+    analyzed INTEGER DEFAULT 0,
+    meta INTEGER DEFAULT 0,
+    empty INTEGER DEFAULT 0,
+    failed INTEGER DEFAULT 0,
+
+    llm_calls INTEGER DEFAULT 0,
+    prompt_tokens INTEGER DEFAULT 0,
+    completion_tokens INTEGER DEFAULT 0,
+    llm_latency_ms INTEGER DEFAULT 0,
+
+    error TEXT
+);
+
+CREATE INDEX idx_crawl_runs_channel ON crawl_runs (source, channel, started_at);
+
+-- ======================
+-- Locks
+-- ======================
+CREATE TABLE locks (
+    name TEXT PRIMARY KEY,
+    owner TEXT NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
 -- ======================
 -- Problem ↔ Idea
 -- ======================
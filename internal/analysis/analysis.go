@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/ratelimit"
 )
 
 type Analyzer struct {
-	apiKey string
-	model  string
+	apiKey  string
+	model   string
+	limiter *ratelimit.Limiter
 }
 
 type AnalysisResultProblem struct {
@@ -37,6 +40,11 @@ type AnalysisResultProduct struct {
 	Description string   `json:"description"`
 	URL         string   `json:"url"`
 	Categories  []string `json:"categories"`
+
+	// Existing marks a product that is already in the database, such as
+	// the subject of imported reviews. The grouper links to it by name
+	// instead of creating a new product.
+	Existing bool `json:"existing,omitempty"`
 }
 
 // AnalysisResult holds the structured output from the LLM after analyzing a post for problem, idea, and products.
@@ -45,6 +53,30 @@ type AnalysisResult struct {
 	Problem  AnalysisResultProblem   `json:"problem"`
 	Idea     AnalysisResultIdea      `json:"idea"`
 	Products []AnalysisResultProduct `json:"products"`
+
+	Usage Usage `json:"-"`
+}
+
+// Usage is what the LLM call behind a result cost.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration // Time spent waiting for the API, rate limiting excluded
+}
+
+// UsageError is returned when the LLM was called but its reply could not be
+// used, so what the call cost is still known.
+type UsageError struct {
+	Usage Usage
+	Err   error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
 }
 
 const PROMPT = `
@@ -100,10 +132,103 @@ If the text is a meta-post (e.g., "Share your project"), set "is_meta" to true a
 - this is list categories, please select category from this list: ['technology', 'healthcare', 'finance', 'education', 'e-commerce', 'productivity', 'communication', 'entertainment', 'travel', 'food-beverage', 'fitness', 'real-estate', 'transportation', 'automotive', 'fashion', 'beauty', 'home-garden', 'pets', 'sports', 'gaming', 'music', 'art-design', 'photography', 'legal', 'hr-recruiting', 'marketing', 'sales', 'customer-service', 'analytics', 'security', 'sustainability', 'social-media', 'ai-ml', 'iot', 'blockchain', 'saas', 'mobile', 'web', 'hardware', 'infrastructure']
 `
 
+const PRODUCTS_PROMPT = `
+You will check a comment from a "share what you're building" thread on reddit. People reply to these threads to pitch the product they are working on, my 'IdeaDB' web site will list these products.
+
+Extract the products the author is building or promoting and return them in a JSON object with a single field: "products".
+
+### For each Product:
+- **name**: The name of the product or startup.
+- **description**: A brief description of what the product does. (In well markdown format, with heading).
+- **url**: The URL of the product, if available.
+- **categories**: Categories of product, in array format.
+
+## Output Expectations
+- The final output must be a single JSON object.
+- If the comment does not pitch any product (questions, jokes, replies to other people), return an empty "products" array.
+- Do NOT mention personal details of the author.
+- categories should be 2 -> 5 item, in this list: [technology, healthcare, finance, education, e-commerce, productivity, communication, entertainment, travel, food-beverage, fitness, real-estate, transportation, automotive, fashion, beauty, home-garden, pets, sports, gaming, music, art-design, photography, legal, hr-recruiting, marketing, sales, customer-service, analytics, security, sustainability, social-media, ai-ml, iot, blockchain, saas, mobile, web, hardware, infrastructure]
+`
+
+const REVIEWS_PROMPT = `
+You will check a batch of user reviews of the product "%s", taken from an app store or a review site. My 'IdeaDB' web site lists painful problems that startup founders could solve, unhappy reviews describe them very precisely.
+
+Find the main problem the reviewers have with the product and return it in a JSON object with these fields: "problem", "idea".
+
+### For Problem:
+- **title**: A concise summary of the core problem, without the product name.
+- **description**: A clear explanation of the problem, who has it, and its consequences, based on what the reviewers say (In well markdown format, with heading).
+- **pain_points**: 2-5 specific user pain points, quoting the reviews when they are precise.
+- **score**: Score of the problem in realword, can profit, 0-100. Higher when many reviews share it.
+- **categories**: Categories of problem, in array format.
+
+### For Idea:
+- **title**: A concise summary of a product that would solve the problem better.
+- **description**: A clear explanation of the idea, how it works, and its potential (In well markdown format, with heading).
+- **features**: 2-5 key features of the proposed solution.
+- **score**: Score of the idea in realword, can profit, 0-100
+- **categories**: Categories of idea, in array format.
+
+## Output Expectations
+- The final output must be a single JSON object.
+- Ignore complaints about billing disputes, single bugs, customer support of one person, or praise. If the reviews share no real problem, give the problem a score of 0.
+- If there is no good idea, give the idea a score of 0.
+- Do NOT mention personal details of the reviewers.
+- categories should be 2 -> 5 item, in this list: [technology, healthcare, finance, education, e-commerce, productivity, communication, entertainment, travel, food-beverage, fitness, real-estate, transportation, automotive, fashion, beauty, home-garden, pets, sports, gaming, music, art-design, photography, legal, hr-recruiting, marketing, sales, customer-service, analytics, security, sustainability, social-media, ai-ml, iot, blockchain, saas, mobile, web, hardware, infrastructure]
+`
+
+var problemSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"title", "description", "pain_points", "score", "categories"},
+	"properties": map[string]any{
+		"title":       map[string]any{"type": "string"},
+		"description": map[string]any{"type": "string"},
+		"pain_points": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"score":       map[string]any{"type": "integer"},
+		"categories": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+}
+
+var ideaSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"title", "description", "features", "score", "categories"},
+	"properties": map[string]any{
+		"title":       map[string]any{"type": "string"},
+		"description": map[string]any{"type": "string"},
+		"features":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"score":       map[string]any{"type": "integer"},
+		"categories": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+}
+
+var productsSchema = map[string]any{
+	"type": "array",
+	"items": map[string]any{
+		"type":     "object",
+		"required": []string{"name", "description", "url"},
+		"properties": map[string]any{
+			"name":        map[string]any{"type": "string"},
+			"description": map[string]any{"type": "string"},
+			"url":         map[string]any{"type": "string"},
+			"categories": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+	},
+}
+
 func New(ctx context.Context, cnf config.Config) (*Analyzer, error) {
 	return &Analyzer{
-		apiKey: cnf.Mistral.APIKey,
-		model:  cnf.Mistral.Model,
+		apiKey:  cnf.Mistral.APIKey,
+		model:   cnf.Mistral.Model,
+		limiter: ratelimit.PerMinute(cnf.Mistral.RequestsPerMinute),
 	}, nil
 }
 
@@ -138,10 +263,133 @@ type MistralChatResponse struct {
 		Index   int            `json:"index"`
 		Message MistralMessage `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Focus hints the analyzer at what a community mostly posts about.
+type Focus string
+
+const (
+	FocusAll      Focus = ""
+	FocusProducts Focus = "products"
+	FocusProblems Focus = "problems"
+)
+
+var focusHints = map[Focus]string{
+	FocusProducts: "\n## Hint\nThis post comes from a community where people mostly present products they built. Focus on extracting the products, only fill the problem and idea when they are clearly stated.\n",
+	FocusProblems: "\n## Hint\nThis post comes from a community where people mostly describe problems they have. Focus on extracting the problem, only list products that are explicitly named.\n",
 }
 
 func (a *Analyzer) ExtractAnalysis(ctx context.Context, text string) (*AnalysisResult, error) {
-	prompt := PROMPT + "\n\nPost:\n" + text
+	return a.ExtractAnalysisWithFocus(ctx, text, FocusAll)
+}
+
+// ExtractAnalysisWithFocus is ExtractAnalysis with a hint about which
+// entities the text most likely contains.
+func (a *Analyzer) ExtractAnalysisWithFocus(ctx context.Context, text string, focus Focus) (*AnalysisResult, error) {
+	prompt := PROMPT + focusHints[focus] + "\n\nPost:\n" + text
+
+	content, usage, err := a.chat(ctx, prompt, MistralJSONSchema{
+		Name: "entity_analysis",
+		Schema: map[string]any{
+			"type":     "object",
+			"required": []string{"problem", "idea", "products", "is_meta"},
+			"properties": map[string]any{
+				"problem":  problemSchema,
+				"idea":     ideaSchema,
+				"products": productsSchema,
+				"is_meta":  map[string]any{"type": "boolean"},
+			},
+		},
+		Strict: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := AnalysisResult{Usage: usage}
+	if err := json.Unmarshal([]byte(content), &analysis); err != nil {
+		log.Printf("%s", content)
+		return nil, &UsageError{Usage: usage, Err: fmt.Errorf("failed to unmarshal analysis: %w", err)}
+	}
+
+	return &analysis, nil
+}
+
+// ExtractProducts only looks for product pitches in text, it is used for the
+// comments of sharing threads. Problem and idea are left with a zero score.
+func (a *Analyzer) ExtractProducts(ctx context.Context, text string) (*AnalysisResult, error) {
+	prompt := PRODUCTS_PROMPT + "\n\nComment:\n" + text
+
+	content, usage, err := a.chat(ctx, prompt, MistralJSONSchema{
+		Name: "product_extraction",
+		Schema: map[string]any{
+			"type":     "object",
+			"required": []string{"products"},
+			"properties": map[string]any{
+				"products": productsSchema,
+			},
+		},
+		Strict: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := AnalysisResult{Usage: usage}
+	if err := json.Unmarshal([]byte(content), &analysis); err != nil {
+		log.Printf("%s", content)
+		return nil, &UsageError{Usage: usage, Err: fmt.Errorf("failed to unmarshal products: %w", err)}
+	}
+
+	return &analysis, nil
+}
+
+// ExtractReviewProblems analyzes reviews of an existing product. The
+// result lists that product as Existing so the problem is linked to it.
+func (a *Analyzer) ExtractReviewProblems(ctx context.Context, product string, text string) (*AnalysisResult, error) {
+	prompt := fmt.Sprintf(REVIEWS_PROMPT, product) + "\n\nReviews:\n" + text
+
+	content, usage, err := a.chat(ctx, prompt, MistralJSONSchema{
+		Name: "review_analysis",
+		Schema: map[string]any{
+			"type":     "object",
+			"required": []string{"problem", "idea"},
+			"properties": map[string]any{
+				"problem": problemSchema,
+				"idea":    ideaSchema,
+			},
+		},
+		Strict: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := AnalysisResult{Usage: usage}
+	if err := json.Unmarshal([]byte(content), &analysis); err != nil {
+		log.Printf("%s", content)
+		return nil, &UsageError{Usage: usage, Err: fmt.Errorf("failed to unmarshal review analysis: %w", err)}
+	}
+
+	if analysis.Problem.Score > 0 || analysis.Idea.Score > 0 {
+		analysis.Products = []AnalysisResultProduct{{Name: product, Existing: true}}
+	}
+
+	return &analysis, nil
+}
+
+// chat sends a single user prompt to Mistral and returns the JSON content
+// of the reply, constrained by schema. It is safe for concurrent use and
+// waits for mistral.requests_per_minute.
+func (a *Analyzer) chat(ctx context.Context, prompt string, schema MistralJSONSchema) (string, Usage, error) {
+	var usage Usage
+	if err := a.limiter.Wait(ctx); err != nil {
+		return "", usage, err
+	}
 
 	reqBody := MistralChatRequest{
 		Model: a.model,
@@ -149,68 +397,14 @@ func (a *Analyzer) ExtractAnalysis(ctx context.Context, text string) (*AnalysisR
 			{Role: "user", Content: prompt},
 		},
 		ResponseFormat: &MistralResponseFormat{
-			Type: "json_object",
-			JSONSchema: MistralJSONSchema{
-				Name: "entity_analysis",
-				Schema: map[string]any{
-					"type":     "object",
-					"required": []string{"problem", "idea", "products", "is_meta"},
-					"properties": map[string]any{
-						"problem": map[string]any{
-							"type":     "object",
-							"required": []string{"title", "description", "pain_points", "score", "categories"},
-							"properties": map[string]any{
-								"title":       map[string]any{"type": "string"},
-								"description": map[string]any{"type": "string"},
-								"pain_points": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
-								"score":       map[string]any{"type": "integer"},
-								"categories": map[string]any{
-									"type":  "array",
-									"items": map[string]any{"type": "string"},
-								},
-							},
-						},
-						"idea": map[string]any{
-							"type":     "object",
-							"required": []string{"title", "description", "features", "score", "categories"},
-							"properties": map[string]any{
-								"title":       map[string]any{"type": "string"},
-								"description": map[string]any{"type": "string"},
-								"features":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
-								"score":       map[string]any{"type": "integer"},
-								"categories": map[string]any{
-									"type":  "array",
-									"items": map[string]any{"type": "string"},
-								},
-							},
-						},
-						"products": map[string]any{
-							"type": "array",
-							"items": map[string]any{
-								"type":     "object",
-								"required": []string{"name", "description", "url"},
-								"properties": map[string]any{
-									"name":        map[string]any{"type": "string"},
-									"description": map[string]any{"type": "string"},
-									"url":         map[string]any{"type": "string"},
-									"categories": map[string]any{
-										"type":  "array",
-										"items": map[string]any{"type": "string"},
-									},
-								},
-							},
-						},
-						"is_meta": map[string]any{"type": "boolean"},
-					},
-				},
-				Strict: true,
-			},
+			Type:       "json_object",
+			JSONSchema: schema,
 		},
 	}
 
 	raw, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", usage, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx,
@@ -219,40 +413,46 @@ func (a *Analyzer) ExtractAnalysis(ctx context.Context, text string) (*AnalysisR
 		bytes.NewBuffer(raw),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", usage, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+a.apiKey)
 
+	start := time.Now()
+	// failed returns the error of a call that was made, with what it cost
+	failed := func(usage Usage, err error) (string, Usage, error) {
+		usage.Latency = time.Since(start)
+		return "", usage, &UsageError{Usage: usage, Err: err}
+	}
+
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call Mistral API: %w", err)
+		return failed(usage, fmt.Errorf("failed to call Mistral API: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errBody bytes.Buffer
 		errBody.ReadFrom(resp.Body)
-		return nil, fmt.Errorf("Mistral API error (status %d): %s", resp.StatusCode, errBody.String())
+		return failed(usage, fmt.Errorf("Mistral API error (status %d): %s", resp.StatusCode, errBody.String()))
 	}
 
 	var mistralResp MistralChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&mistralResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return failed(usage, fmt.Errorf("failed to decode response: %w", err))
 	}
 
-	if len(mistralResp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in response")
+	usage = Usage{
+		PromptTokens:     mistralResp.Usage.PromptTokens,
+		CompletionTokens: mistralResp.Usage.CompletionTokens,
 	}
-
-	var analysis AnalysisResult
-	if err := json.Unmarshal([]byte(mistralResp.Choices[0].Message.Content), &analysis); err != nil {
-		log.Printf("%s", mistralResp.Choices[0].Message.Content)
-		return nil, fmt.Errorf("failed to unmarshal analysis: %w", err)
+	if len(mistralResp.Choices) == 0 {
+		return failed(usage, fmt.Errorf("no choices in response"))
 	}
 
-	return &analysis, nil
+	usage.Latency = time.Since(start)
+	return mistralResp.Choices[0].Message.Content, usage, nil
 }
 
 type OllamaEmbeddingRequest struct {
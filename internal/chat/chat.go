@@ -0,0 +1,145 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+)
+
+// Message is a single chat message, normalized across export formats.
+type Message struct {
+	ID       string
+	Channel  string
+	Author   string
+	Text     string
+	Time     time.Time
+	ThreadID string // Set when the platform groups replies in threads (Slack)
+}
+
+// Thread is a group of messages analyzed together: a platform thread or a
+// conversation window of messages close in time.
+type Thread struct {
+	ID       string // Channel and ID of the first message, stable across re-imports
+	Channel  string
+	Messages []*Message
+}
+
+// GroupOptions controls how messages outside platform threads are cut into
+// conversation windows.
+type GroupOptions struct {
+	MaxGap      time.Duration // A longer silence starts a new window
+	MaxMessages int           // Windows are cut after this many messages
+}
+
+var DefaultGroupOptions = GroupOptions{
+	MaxGap:      30 * time.Minute,
+	MaxMessages: 50,
+}
+
+// Load reads an export file, or for Slack an export directory, in the given
+// format: discord, slack or telegram.
+func Load(format, path string) ([]*Message, error) {
+	switch format {
+	case "discord":
+		return loadDiscord(path)
+	case "slack":
+		return loadSlack(path)
+	case "telegram":
+		return loadTelegram(path)
+	default:
+		return nil, fmt.Errorf("unknown chat export format %q, expected discord, slack or telegram", format)
+	}
+}
+
+// Group sorts messages by time and groups them per channel into platform
+// threads and conversation windows.
+func Group(messages []*Message, opts GroupOptions) []*Thread {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Time.Before(messages[j].Time)
+	})
+
+	// Some exports only mark the replies, attach the parent to its thread
+	parents := map[string]bool{}
+	for _, m := range messages {
+		if m.ThreadID != "" {
+			parents[m.Channel+"/"+m.ThreadID] = true
+		}
+	}
+	for _, m := range messages {
+		if m.ThreadID == "" && parents[m.Channel+"/"+m.ID] {
+			m.ThreadID = m.ID
+		}
+	}
+
+	var threads []*Thread
+	byThreadID := map[string]*Thread{}
+	windows := map[string]*Thread{} // Open window per channel
+
+	for _, m := range messages {
+		if strings.TrimSpace(m.Text) == "" {
+			continue
+		}
+
+		if m.ThreadID != "" {
+			key := m.Channel + "/" + m.ThreadID
+			t, ok := byThreadID[key]
+			if !ok {
+				t = &Thread{ID: threadID(m.Channel, m.ThreadID), Channel: m.Channel}
+				byThreadID[key] = t
+				threads = append(threads, t)
+			}
+			t.Messages = append(t.Messages, m)
+			continue
+		}
+
+		w := windows[m.Channel]
+		if w != nil {
+			last := w.Messages[len(w.Messages)-1]
+			if m.Time.Sub(last.Time) > opts.MaxGap || len(w.Messages) >= opts.MaxMessages {
+				w = nil
+			}
+		}
+		if w == nil {
+			w = &Thread{ID: threadID(m.Channel, m.ID), Channel: m.Channel}
+			windows[m.Channel] = w
+			threads = append(threads, w)
+		}
+		w.Messages = append(w.Messages, m)
+	}
+
+	return threads
+}
+
+// Text renders the thread as "author: message" lines.
+func (t *Thread) Text() string {
+	var b strings.Builder
+	for _, m := range t.Messages {
+		fmt.Fprintf(&b, "%s: %s\n", m.Author, strings.TrimSpace(m.Text))
+	}
+	return b.String()
+}
+
+// Title is the start of the first message, on one line.
+func (t *Thread) Title() string {
+	return htmltext.Headline(t.Messages[0].Text, 100)
+}
+
+// Authors lists the distinct authors in order of appearance.
+func (t *Thread) Authors() []string {
+	var authors []string
+	seen := map[string]bool{}
+	for _, m := range t.Messages {
+		if !seen[m.Author] {
+			seen[m.Author] = true
+			authors = append(authors, m.Author)
+		}
+	}
+	return authors
+}
+
+func threadID(channel, messageID string) string {
+	return channel + ":" + messageID
+}
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// discordExport is the JSON format of DiscordChatExporter, one file per
+// channel.
+type discordExport struct {
+	Guild struct {
+		Name string `json:"name"`
+	} `json:"guild"`
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	Messages []struct {
+		ID        string    `json:"id"`
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Content   string    `json:"content"`
+		Author    struct {
+			Name     string `json:"name"`
+			Nickname string `json:"nickname"`
+			IsBot    bool   `json:"isBot"`
+		} `json:"author"`
+	} `json:"messages"`
+}
+
+func loadDiscord(path string) ([]*Message, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var export discordExport
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse discord export %s: %w", path, err)
+	}
+
+	channel := export.Channel.Name
+	if export.Guild.Name != "" {
+		channel = export.Guild.Name + "/" + channel
+	}
+
+	messages := make([]*Message, 0, len(export.Messages))
+	for _, m := range export.Messages {
+		if m.Author.IsBot || (m.Type != "" && m.Type != "Default" && m.Type != "Reply") {
+			continue
+		}
+		author := m.Author.Nickname
+		if author == "" {
+			author = m.Author.Name
+		}
+		messages = append(messages, &Message{
+			ID:      m.ID,
+			Channel: channel,
+			Author:  author,
+			Text:    m.Content,
+			Time:    m.Timestamp,
+		})
+	}
+	return messages, nil
+}
@@ -0,0 +1,147 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Slack exports are a directory with a users.json file and one folder per
+// channel holding a JSON file per day.
+type slackMessage struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+	UserProfile struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"user_profile"`
+}
+
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+}
+
+// loadSlack reads a whole export directory, a channel folder or a single
+// day file. The channel is the name of the folder holding the day files.
+func loadSlack(path string) ([]*Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadSlackDay(path, nil)
+	}
+
+	// The export root holds users.json next to the channel folders, a
+	// channel folder has it in its parent.
+	users := loadSlackUsers(filepath.Join(path, "users.json"))
+	isRoot := users != nil
+	if !isRoot {
+		users = loadSlackUsers(filepath.Join(path, "..", "users.json"))
+	}
+
+	var messages []*Message
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		// Files at the top of the export root are metadata, not messages
+		if isRoot && filepath.Dir(p) == filepath.Clean(path) {
+			return nil
+		}
+		day, err := loadSlackDay(p, users)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, day...)
+		return nil
+	})
+	return messages, err
+}
+
+func loadSlackDay(path string, users map[string]string) ([]*Message, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var day []slackMessage
+	if err := json.Unmarshal(raw, &day); err != nil {
+		return nil, fmt.Errorf("failed to parse slack export %s: %w", path, err)
+	}
+
+	channel := filepath.Base(filepath.Dir(path))
+	messages := make([]*Message, 0, len(day))
+	for _, m := range day {
+		// Joins, leaves and bot posts carry a subtype
+		if m.Type != "message" || (m.Subtype != "" && m.Subtype != "thread_broadcast") {
+			continue
+		}
+
+		author := m.UserProfile.DisplayName
+		if author == "" {
+			author = m.UserProfile.RealName
+		}
+		if author == "" {
+			author = users[m.User]
+		}
+		if author == "" {
+			author = m.User
+		}
+
+		messages = append(messages, &Message{
+			ID:       m.TS,
+			Channel:  channel,
+			Author:   author,
+			Text:     m.Text,
+			Time:     parseSlackTS(m.TS),
+			ThreadID: m.ThreadTS,
+		})
+	}
+	return messages, nil
+}
+
+func loadSlackUsers(path string) map[string]string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var list []slackUser
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil
+	}
+	users := make(map[string]string, len(list))
+	for _, u := range list {
+		users[u.ID] = u.RealName
+		if users[u.ID] == "" {
+			users[u.ID] = u.Name
+		}
+	}
+	return users
+}
+
+// parseSlackTS reads Slack message IDs, which are unix timestamps with
+// microseconds such as "1700000000.000100".
+func parseSlackTS(ts string) time.Time {
+	secs, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	us, _ := strconv.ParseInt(frac, 10, 64)
+	return time.Unix(s, us*int64(time.Microsecond))
+}
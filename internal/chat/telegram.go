@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// telegramExport is the result.json of a Telegram Desktop chat export.
+type telegramExport struct {
+	Name     string `json:"name"`
+	ID       int64  `json:"id"`
+	Messages []struct {
+		ID           int64        `json:"id"`
+		Type         string       `json:"type"`
+		DateUnixtime string       `json:"date_unixtime"`
+		Date         string       `json:"date"`
+		From         string       `json:"from"`
+		Text         telegramText `json:"text"`
+	} `json:"messages"`
+}
+
+// telegramText is either a string or a list mixing strings and formatted
+// entities such as {"type": "link", "text": "..."}.
+type telegramText string
+
+func (t *telegramText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = telegramText(s)
+		return nil
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		var s string
+		if err := json.Unmarshal(part, &s); err == nil {
+			b.WriteString(s)
+			continue
+		}
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &entity); err == nil {
+			b.WriteString(entity.Text)
+		}
+	}
+	*t = telegramText(b.String())
+	return nil
+}
+
+func loadTelegram(path string) ([]*Message, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var export telegramExport
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse telegram export %s: %w", path, err)
+	}
+
+	channel := export.Name
+	if channel == "" {
+		channel = strconv.FormatInt(export.ID, 10)
+	}
+
+	messages := make([]*Message, 0, len(export.Messages))
+	for _, m := range export.Messages {
+		if m.Type != "message" {
+			continue
+		}
+		messages = append(messages, &Message{
+			ID:      strconv.FormatInt(m.ID, 10),
+			Channel: channel,
+			Author:  m.From,
+			Text:    string(m.Text),
+			Time:    parseTelegramDate(m.DateUnixtime, m.Date),
+		})
+	}
+	return messages, nil
+}
+
+func parseTelegramDate(unix, date string) time.Time {
+	if secs, err := strconv.ParseInt(unix, 10, 64); err == nil {
+		return time.Unix(secs, 0)
+	}
+	t, _ := time.ParseInLocation("2006-01-02T15:04:05", date, time.Local)
+	return t
+}
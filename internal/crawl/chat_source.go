@@ -0,0 +1,82 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/letieu/idea-extractor/internal/chat"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// Conversations shorter than this are mostly greetings and not worth an
+// analysis call.
+const minChatThreadLength = 200
+
+// chatSource imports Discord, Slack or Telegram exports. Every thread or
+// conversation window becomes one item, keyed by its channel and first
+// message so re-importing an export does not duplicate it.
+type chatSource struct {
+	format  string
+	paths   []string
+	options chat.GroupOptions
+}
+
+// NewChatSource creates a source reading the given export files of format
+// discord, slack or telegram.
+func NewChatSource(format string, paths []string) (Source, error) {
+	switch format {
+	case "discord", "slack", "telegram":
+	default:
+		return nil, fmt.Errorf("unknown chat export format %q, expected discord, slack or telegram", format)
+	}
+	return &chatSource{format: format, paths: paths, options: chat.DefaultGroupOptions}, nil
+}
+
+func (s *chatSource) Name() string {
+	return s.format
+}
+
+func (s *chatSource) Channel() string {
+	return strings.Join(s.paths, ", ")
+}
+
+func (s *chatSource) ListItems(ctx context.Context) ([]*Item, error) {
+	var messages []*chat.Message
+	for _, path := range s.paths {
+		loaded, err := chat.Load(s.format, path)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, loaded...)
+	}
+
+	threads := chat.Group(messages, s.options)
+	items := make([]*Item, 0, len(threads))
+	for _, t := range threads {
+		first := t.Messages[0]
+		text := t.Text()
+		item := &Item{
+			SourceItem: database.SourceItem{
+				Channel:         t.Channel,
+				SourceItemID:    t.ID,
+				Title:           t.Title(),
+				Content:         text,
+				Author:          strings.Join(t.Authors(), ", "), // Everyone who took part, starter first
+				SourceCreatedAt: first.Time,
+				NumComments:     len(t.Messages) - 1,
+				IsSelf:          true,
+			},
+		}
+		if len(text) < minChatThreadLength {
+			item.Skip = "too short"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// FetchComments is not supported, replies are already part of the thread.
+func (s *chatSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	return nil, fmt.Errorf("chat threads have no separate comments")
+}
@@ -3,24 +3,39 @@ package crawl
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/letieu/idea-extractor/config"
 	"github.com/letieu/idea-extractor/internal/analysis"
 	"github.com/letieu/idea-extractor/internal/database"
-	"github.com/letieu/idea-extractor/internal/reddit"
 )
 
 type Crawler struct {
-	redditClient *reddit.RedditClient
-	db           CrawlerStore
-	analyzer     *analysis.Analyzer
-	config       *config.Config
+	sources  []Source
+	db       CrawlerStore
+	analyzer *analysis.Analyzer
+	config   *config.Config
+
+	// Set by NewDryRun, results are also written to dryRun as JSON lines
+	dryRun      io.Writer
+	maxLLMCalls int          // 0 for no limit
+	llmCalls    atomic.Int64 // Items queued for the analyzer
 }
 
 type CrawlerStore interface {
 	SourceItemExists(source string, sourceItemID string) (bool, error)
 	CreateSourceItem(item *database.SourceItem, analysisResult string) error
+	UpdateSourceItemAnalysis(item *database.SourceItem) error
+	GetRetryableSourceItems(limit int) ([]*database.SourceItem, error)
+	CreateCrawlRun(run *database.CrawlRun) (int, error)
+	UpdateCrawlRun(run *database.CrawlRun) error
 	Close() error
 }
 
@@ -37,23 +52,30 @@ func New(ctx context.Context) (*Crawler, error) {
 		return nil, err
 	}
 
-	anl, err := analysis.New(ctx, *cfg)
+	c, err := newCrawler(ctx, cfg, db)
 	if err != nil {
 		log.Fatal(err)
 		return nil, err
 	}
+	return c, nil
+}
 
-	redditClient, err := reddit.NewClient()
+func newCrawler(ctx context.Context, cfg *config.Config, store CrawlerStore) (*Crawler, error) {
+	anl, err := analysis.New(ctx, *cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := newSources(cfg)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
 	return &Crawler{
-		redditClient: redditClient,
-		db:           db,
-		analyzer:     anl,
-		config:       cfg,
+		sources:  sources,
+		db:       store,
+		analyzer: anl,
+		config:   cfg,
 	}, nil
 }
 
@@ -61,79 +83,328 @@ func (c *Crawler) Close() error {
 	return c.db.Close()
 }
 
-func (c *Crawler) CrawlAll(ctx context.Context) {
-	for _, subreddit := range c.config.Crawler.Subreddits {
-		err := c.CrawlSubreddit(ctx, subreddit)
+// CrawlAll crawls every configured source. Items are analyzed by
+// crawler.concurrency workers while the next sources are fetched. It stops
+// fetching once ctx is cancelled and returns the context error.
+func (c *Crawler) CrawlAll(ctx context.Context) error {
+	return c.crawlSources(ctx, c.sources)
+}
+
+// CrawlChannels crawls the configured channels of the source named source,
+// or all of them when channels is empty. Channel names are matched without
+// case, e.g. subreddits or Hacker News list names.
+func (c *Crawler) CrawlChannels(ctx context.Context, source string, channels []string) error {
+	var sources []Source
+	for _, src := range c.sources {
+		if src.Name() != source {
+			continue
+		}
+		if len(channels) > 0 && !slices.ContainsFunc(channels, func(ch string) bool { return strings.EqualFold(ch, src.Channel()) }) {
+			continue
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no configured %s source matches %v", source, channels)
+	}
+	return c.crawlSources(ctx, sources)
+}
+
+func (c *Crawler) crawlSources(ctx context.Context, sources []Source) error {
+	p := c.startPipeline(ctx)
+	defer p.wait()
+
+	for _, src := range sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if c.llmLimitReached() {
+			log.Printf("LLM call limit of %d reached, not crawling the remaining sources", c.maxLLMCalls)
+			break
+		}
+		err := c.crawlSource(ctx, p, src)
 		if err != nil {
-			log.Printf("Error when crawl subreddit: %s, error: %v", subreddit, err)
+			log.Printf("Error when crawl %s %s, error: %v", src.Name(), src.Channel(), err)
 		}
 	}
+	return ctx.Err()
 }
 
-func (c *Crawler) CrawlSubreddit(ctx context.Context, subreddit string) error {
-	log.Printf("Crawling r/%s for problems, ideas, and products...", subreddit)
-	posts, err := c.redditClient.FetchPosts(ctx, subreddit, c.config.Crawler.PostLimit)
+// CrawlSource crawls a single source and waits for its items to be
+// analyzed and stored.
+func (c *Crawler) CrawlSource(ctx context.Context, src Source) error {
+	p := c.startPipeline(ctx)
+	defer p.wait()
+
+	return c.crawlSource(ctx, p, src)
+}
+
+func (c *Crawler) crawlSource(ctx context.Context, p *pipeline, src Source) error {
+	log.Printf("Crawling %s %s for problems, ideas, and products...", src.Name(), src.Channel())
+	run := p.startRun(src.Name(), src.Channel())
+	items, err := src.ListItems(ctx)
+	if err != nil {
+		log.Printf("Error fetching items from %s %s: %v", src.Name(), src.Channel(), err)
+		run.add(func(r *database.CrawlRun) { r.Error = err.Error() })
+		return err
+	}
+	run.add(func(r *database.CrawlRun) { r.Fetched = len(items) })
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if c.llmLimitReached() {
+			break
+		}
+		c.processItem(ctx, p, src, item)
+	}
+
+	return nil
+}
+
+// Backfill walks the new listing of a subreddit back in time and processes
+// every post until it reaches one created before until, or the post with
+// ID untilID. A zero until and empty untilID walk as far as Reddit allows.
+func (c *Crawler) Backfill(ctx context.Context, subreddit string, until time.Time, untilID string) error {
+	var src *redditSource
+	for _, s := range c.sources {
+		if rs, ok := s.(*redditSource); ok {
+			src = &redditSource{client: rs.client, config: c.config, subreddit: c.subredditConfig(subreddit)}
+			break
+		}
+	}
+	if src == nil {
+		return fmt.Errorf("reddit is not enabled in crawler.sources")
+	}
+
+	log.Printf("Backfilling r/%s...", subreddit)
+	p := c.startPipeline(ctx)
+	run := p.startRun(src.Name(), src.Channel())
+	err := src.backfill(ctx, until, untilID, func(item *Item) {
+		run.add(func(r *database.CrawlRun) { r.Fetched++ })
+		c.processItem(ctx, p, src, item)
+	})
+	if err != nil {
+		run.add(func(r *database.CrawlRun) { r.Error = err.Error() })
+	}
+	p.wait()
 	if err != nil {
 		log.Printf("Error fetching posts from r/%s: %v", subreddit, err)
 		return err
 	}
 
-	for _, post := range posts {
-		existed, err := c.db.SourceItemExists("reddit", post.ID)
+	log.Printf("Backfill of r/%s done", subreddit)
+	return nil
+}
+
+// subredditConfig returns the configured rules for a subreddit, or the
+// defaults when it is not in crawler.subreddits.
+func (c *Crawler) subredditConfig(name string) config.Subreddit {
+	for _, sub := range c.config.Crawler.Subreddits {
+		if strings.EqualFold(sub.Name, name) {
+			return sub
+		}
+	}
+	return config.Subreddit{Name: name, Listing: "new"}
+}
+
+// processItem runs the fetching side of an item: filters, the existence
+// check and its comments. The item is then queued for analysis.
+func (c *Crawler) processItem(ctx context.Context, p *pipeline, src Source, item *Item) {
+	item.Source = src.Name()
+	if item.Channel == "" {
+		item.Channel = src.Channel()
+	}
+
+	if item.SharingThread && c.isSharingThread(item.Title) {
+		c.processSharingThread(ctx, p, src, item)
+		return
+	}
+
+	if item.Skip != "" {
+		log.Printf("Item is %s, ignoring: %s", item.Skip, item.Title)
+		p.current.add(func(r *database.CrawlRun) { r.SkippedFiltered++ })
+		return
+	}
+
+	existed, err := c.db.SourceItemExists(item.Source, item.SourceItemID)
+	if err != nil {
+		log.Printf("Fail to check source item existence %v", err)
+		return
+	}
+
+	if existed {
+		log.Printf("Source item already existed, ignoring: %s", item.Title)
+		p.current.add(func(r *database.CrawlRun) { r.SkippedExisting++ })
+		return
+	}
+
+	log.Printf("Found new item: %s", item.Title)
+
+	if item.WithComments {
+		comments, err := src.FetchComments(ctx, item)
 		if err != nil {
-			log.Printf("Fail to check source item existence %v", err)
-			continue
+			log.Printf("Failed to fetch comments of %s, analyzing without them: %v", item.SourceItemID, err)
 		}
+		appendComments(item, comments)
+	}
 
-		if existed {
-			log.Printf("Source item already existed, ignoring: %s", post.Title)
-			continue
+	p.submit(ctx, job{item: item})
+}
+
+// saveItem stores an analyzed item with its status: analyzed, or meta and
+// empty for rejected items, which are kept so they are not analyzed again.
+func (c *Crawler) saveItem(item *Item, analysisResult *analysis.AnalysisResult, status string) {
+	analysisResultBytes, err := json.Marshal(analysisResult)
+	if err != nil {
+		log.Printf("Failed to marshal analysis result: %v", err)
+		return
+	}
+	item.AnalysisResult = string(analysisResultBytes)
+	item.Status = status
+	item.LastError = ""
+	item.NextRetryAt = time.Time{}
+
+	if item.ID != 0 {
+		err = c.db.UpdateSourceItemAnalysis(&item.SourceItem)
+	} else {
+		err = c.createItem(item)
+	}
+	if err != nil {
+		log.Printf("Failed to save source item: %v", err)
+	}
+}
+
+// saveFailure stores an item whose analysis failed so RetryFailed picks it
+// up later. Analyses interrupted by a shutdown do not count as attempts.
+func (c *Crawler) saveFailure(item *Item, analysisErr error) {
+	item.LastError = analysisErr.Error()
+	if errors.Is(analysisErr, context.Canceled) {
+		item.Status = database.StatusFailed
+		item.NextRetryAt = time.Now()
+	} else {
+		item.Attempts++
+		if item.Attempts >= c.config.Crawler.RetryMaxAttempts {
+			log.Printf("Giving up on %s after %d attempts", item.SourceItemID, item.Attempts)
+			item.Status = database.StatusAbandoned
+			item.NextRetryAt = time.Time{}
+		} else {
+			item.Status = database.StatusFailed
+			item.NextRetryAt = time.Now().Add(retryDelay(c.config.Crawler.RetryBaseDelay, item.Attempts))
 		}
+	}
 
-		log.Printf("Found new post: %s", post.Title)
+	var err error
+	if item.ID != 0 {
+		err = c.db.UpdateSourceItemAnalysis(&item.SourceItem)
+	} else {
+		err = c.createItem(item)
+	}
+	if err != nil {
+		log.Printf("Failed to save failed source item: %v", err)
+	}
+}
 
-		text := post.Title + "\n" + post.Content
+// createItem stores a new item and lets its source know.
+func (c *Crawler) createItem(item *Item) error {
+	if err := c.db.CreateSourceItem(&item.SourceItem, item.AnalysisResult); err != nil {
+		return err
+	}
+	if item.onSaved != nil {
+		return item.onSaved()
+	}
+	return nil
+}
 
-		analysisResult, err := c.analyzer.ExtractAnalysis(ctx, text)
-		if err != nil {
-			log.Printf("Failed to extract analysis from post: %v", err)
-			continue
+// retryDelay doubles base for every attempt after the first, up to a day.
+func retryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < 24*time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, 24*time.Hour)
+}
+
+// RetryFailed analyzes again up to limit items whose analysis failed and
+// whose retry time has come. Their stored content is reused, nothing is
+// fetched from the sources.
+func (c *Crawler) RetryFailed(ctx context.Context, limit int) error {
+	failed, err := c.db.GetRetryableSourceItems(limit)
+	if err != nil {
+		return fmt.Errorf("failed to get failed source items: %w", err)
+	}
+	log.Printf("Retrying %d failed items", len(failed))
+
+	p := c.startPipeline(ctx)
+	defer p.wait()
+
+	// Retried items are counted in a run of their own, not in the runs of
+	// their sources
+	run := p.startRun("retry", "")
+	run.add(func(r *database.CrawlRun) { r.Fetched = len(failed) })
+
+	for _, sourceItem := range failed {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		if analysisResult.IsMeta {
-			log.Printf("Post is meta, ignoring: %s", post.Title)
-			continue
+		item := &Item{
+			SourceItem: *sourceItem,
+			Focus:      analysis.Focus(sourceItem.AnalysisFocus),
 		}
+		p.submit(ctx, job{item: item, productsOnly: sourceItem.AnalysisMode == "products"})
+	}
+	return nil
+}
 
-		isEmpty := analysisResult.Idea.Score == 0 && analysisResult.Problem.Score == 0 && len(analysisResult.Products) == 0
-		if isEmpty {
-			log.Printf("Empty post, ignore: %s", post.Title)
-			continue
+func (c *Crawler) isSharingThread(title string) bool {
+	title = strings.ToLower(title)
+	for _, keyword := range c.config.Crawler.SharingKeywords {
+		if strings.Contains(title, strings.ToLower(keyword)) {
+			return true
 		}
+	}
+	return false
+}
 
-		analysisResultBytes, err := json.Marshal(analysisResult)
-		if err != nil {
-			log.Printf("Failed to marshal analysis result: %v", err)
+// processSharingThread queues every top-level comment of a "share what
+// you're building" thread to be searched for products and stored as its
+// own source item. The thread itself is not stored so new replies are
+// picked up next run.
+func (c *Crawler) processSharingThread(ctx context.Context, p *pipeline, src Source, thread *Item) {
+	log.Printf("Found sharing thread: %s", thread.Title)
+
+	comments, err := src.FetchComments(ctx, thread)
+	if err != nil {
+		log.Printf("Failed to fetch comments of sharing thread %s: %v", thread.SourceItemID, err)
+		return
+	}
+
+	for _, comment := range comments {
+		if ctx.Err() != nil {
+			return
+		}
+		if comment.Depth > 0 {
 			continue
 		}
-		analysisResultStr := string(analysisResultBytes)
+		p.current.add(func(r *database.CrawlRun) { r.Fetched++ })
 
-		sourceItem := database.SourceItem{
-			Source:          "reddit",
-			SourceItemID:    post.ID,
-			Title:           post.Title,
-			Content:         post.Content,
-			Author:          post.Author,
-			URL:             post.URL,
-			Score:           post.Score,
-			SourceCreatedAt: post.CreatedAt,
-			AnalysisResult:  analysisResultStr,
+		comment.Source = thread.Source
+		if comment.Channel == "" {
+			comment.Channel = thread.Channel
 		}
 
-		if err := c.db.CreateSourceItem(&sourceItem, analysisResultStr); err != nil {
-			log.Printf("Failed to save source item: %v", err)
+		existed, err := c.db.SourceItemExists(comment.Source, comment.SourceItemID)
+		if err != nil {
+			log.Printf("Fail to check source item existence %v", err)
+			continue
+		}
+		if existed {
+			p.current.add(func(r *database.CrawlRun) { r.SkippedExisting++ })
+			continue
 		}
-	}
 
-	return nil
+		p.submit(ctx, job{item: comment, productsOnly: true})
+	}
 }
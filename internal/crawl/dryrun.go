@@ -0,0 +1,101 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// DryRunOptions configures a crawler made by NewDryRun.
+type DryRunOptions struct {
+	Output      io.Writer    // Receives one JSON line per analyzed item
+	Store       *MemoryStore // Receives the items and runs, a new one when nil
+	MaxLLMCalls int          // Items to analyze at most, 0 for no limit
+}
+
+// dryRunResult is a line of dry run output.
+type dryRunResult struct {
+	Source           string                   `json:"source"`
+	Channel          string                   `json:"channel"`
+	SourceItemID     string                   `json:"source_item_id"`
+	Title            string                   `json:"title"`
+	URL              string                   `json:"url"`
+	AnalysisMode     string                   `json:"analysis_mode,omitempty"`
+	Status           string                   `json:"status"` // As it would be stored, or failed
+	Analysis         *analysis.AnalysisResult `json:"analysis,omitempty"`
+	Error            string                   `json:"error,omitempty"`
+	PromptTokens     int                      `json:"prompt_tokens"`
+	CompletionTokens int                      `json:"completion_tokens"`
+}
+
+// NewDryRun creates a crawler that fetches and analyzes items like New but
+// stores them in memory, to try prompts or new channels without touching
+// the data. The analysis of every item is also written to opts.Output.
+func NewDryRun(ctx context.Context, opts DryRunOptions) (*Crawler, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryStore(nil)
+	}
+
+	c, err := newCrawler(ctx, cfg, store)
+	if err != nil {
+		return nil, err
+	}
+	c.dryRun = opts.Output
+	c.maxLLMCalls = opts.MaxLLMCalls
+	return c, nil
+}
+
+// writeDryRun writes the outcome of an analysis as a JSON line. It is only
+// called by the pipeline writer, so lines are never interleaved.
+func (c *Crawler) writeDryRun(r result) {
+	line := dryRunResult{
+		Source:       r.item.Source,
+		Channel:      r.item.Channel,
+		SourceItemID: r.item.SourceItemID,
+		Title:        r.item.Title,
+		URL:          r.item.URL,
+		AnalysisMode: r.item.AnalysisMode,
+		Status:       r.status,
+		Analysis:     r.analysis,
+	}
+	if r.err != nil {
+		line.Status = database.StatusFailed
+		line.Error = r.err.Error()
+	}
+	var usageErr *analysis.UsageError
+	switch {
+	case r.analysis != nil:
+		line.PromptTokens = r.analysis.Usage.PromptTokens
+		line.CompletionTokens = r.analysis.Usage.CompletionTokens
+	case errors.As(r.err, &usageErr):
+		line.PromptTokens = usageErr.Usage.PromptTokens
+		line.CompletionTokens = usageErr.Usage.CompletionTokens
+	}
+
+	if err := json.NewEncoder(c.dryRun).Encode(line); err != nil {
+		log.Printf("Failed to write dry run result: %v", err)
+	}
+}
+
+// reserveLLMCall counts an item queued for the analyzer and reports
+// whether it is still within the limit.
+func (c *Crawler) reserveLLMCall() bool {
+	n := c.llmCalls.Add(1)
+	return c.maxLLMCalls == 0 || n <= int64(c.maxLLMCalls)
+}
+
+func (c *Crawler) llmLimitReached() bool {
+	return c.maxLLMCalls > 0 && c.llmCalls.Load() >= int64(c.maxLLMCalls)
+}
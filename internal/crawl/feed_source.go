@@ -0,0 +1,78 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/feed"
+)
+
+// feedSource crawls one RSS or Atom feed. Entries are deduplicated by
+// their GUID.
+type feedSource struct {
+	client *feed.Client
+	feed   config.Feed
+}
+
+func newFeedSources(cfg *config.Config) ([]Source, error) {
+	client := feed.NewClient()
+
+	sources := make([]Source, 0, len(cfg.Feeds))
+	for _, f := range cfg.Feeds {
+		sources = append(sources, &feedSource{client: client, feed: f})
+	}
+	return sources, nil
+}
+
+func (s *feedSource) Name() string {
+	return "feed"
+}
+
+func (s *feedSource) Channel() string {
+	return s.feed.Name
+}
+
+func (s *feedSource) ListItems(ctx context.Context) ([]*Item, error) {
+	entries, err := s.client.Fetch(ctx, s.feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	if s.feed.Limit > 0 && len(entries) > s.feed.Limit {
+		entries = entries[:s.feed.Limit]
+	}
+
+	items := make([]*Item, 0, len(entries))
+	for _, entry := range entries {
+		item := &Item{
+			SourceItem: database.SourceItem{
+				SourceItemID:    feedItemID(s.feed.URL, entry.GUID),
+				Title:           entry.Title,
+				Content:         entry.Content,
+				Author:          entry.Author,
+				URL:             entry.Link,
+				SourceCreatedAt: entry.Published,
+				IsSelf:          true,
+			},
+			Focus: analysis.Focus(s.feed.Focus),
+		}
+		if entry.GUID == "" {
+			item.Skip = "without guid or link"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// feedItemID scopes a GUID to its feed. All feeds are stored under the
+// same source, and GUIDs such as post numbers are only unique in a feed.
+func feedItemID(feedURL, guid string) string {
+	return feedURL + " " + guid
+}
+
+// FetchComments is not supported, feeds only carry the entries.
+func (s *feedSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	return nil, fmt.Errorf("feeds have no comments")
+}
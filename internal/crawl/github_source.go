@@ -0,0 +1,166 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/github"
+)
+
+// githubSource crawls the labeled issues and the discussions of one
+// repository. Reactions are the score, comments are analyzed as context.
+type githubSource struct {
+	client *github.Client
+	config *config.Config
+	repo   config.GitHubRepo
+}
+
+func newGitHubSources(cfg *config.Config) ([]Source, error) {
+	client := github.NewClient(cfg.GitHub.BaseURL, cfg.GitHub.Token)
+
+	sources := make([]Source, 0, len(cfg.GitHub.Repos))
+	for _, repo := range cfg.GitHub.Repos {
+		sources = append(sources, &githubSource{client: client, config: cfg, repo: repo})
+	}
+	return sources, nil
+}
+
+func (s *githubSource) Name() string {
+	return "github"
+}
+
+func (s *githubSource) Channel() string {
+	return s.repo.Name
+}
+
+func (s *githubSource) ListItems(ctx context.Context) ([]*Item, error) {
+	labels := s.repo.Labels
+	if len(labels) == 0 {
+		labels = []string{""}
+	}
+
+	// The API ANDs labels, so ask once per label and drop duplicates
+	var items []*Item
+	seen := map[int]bool{}
+	for _, label := range labels {
+		issues, err := s.client.ListIssues(ctx, s.repo.Name, label, s.repo.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if seen[issue.Number] {
+				continue
+			}
+			seen[issue.Number] = true
+			items = append(items, s.issueItem(issue))
+		}
+	}
+
+	if s.repo.Discussions {
+		discussions, err := s.client.ListDiscussions(ctx, s.repo.Name, s.repo.DiscussionCategory, s.repo.Limit)
+		if err != nil {
+			// Keep the issues, discussions can be disabled on the repository
+			log.Printf("Failed to list discussions of %s: %v", s.repo.Name, err)
+		}
+		for _, d := range discussions {
+			items = append(items, s.discussionItem(d))
+		}
+	}
+
+	return items, nil
+}
+
+func (s *githubSource) issueItem(issue *github.Issue) *Item {
+	return &Item{
+		SourceItem: database.SourceItem{
+			SourceItemID:    issueID(s.repo.Name, issue.Number),
+			Title:           issue.Title,
+			Content:         issue.Body,
+			Author:          issue.Author,
+			URL:             issue.URL,
+			Score:           issue.Reactions,
+			SourceCreatedAt: issue.CreatedAt,
+			NumComments:     issue.Comments,
+			Flair:           strings.Join(issue.Labels, ", "),
+			IsSelf:          true,
+		},
+		Focus:        analysis.Focus(s.repo.Focus),
+		WithComments: issue.Comments > 0,
+	}
+}
+
+func (s *githubSource) discussionItem(d *github.Discussion) *Item {
+	return &Item{
+		SourceItem: database.SourceItem{
+			SourceItemID:    discussionID(s.repo.Name, d.Number),
+			Title:           d.Title,
+			Content:         d.Body,
+			Author:          d.Author,
+			URL:             d.URL,
+			Score:           d.Reactions,
+			SourceCreatedAt: d.CreatedAt,
+			NumComments:     d.Comments,
+			Flair:           d.Category,
+			IsSelf:          true,
+		},
+		Focus:        analysis.Focus(s.repo.Focus),
+		WithComments: d.Comments > 0,
+	}
+}
+
+func (s *githubSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	var comments []*github.Comment
+	var err error
+	if number, ok := parseNumber(item.SourceItemID, "/discussions/"); ok {
+		comments, err = s.client.ListDiscussionComments(ctx, s.repo.Name, number, s.config.GitHub.MaxComments)
+	} else if number, ok := parseNumber(item.SourceItemID, "#"); ok {
+		comments, err = s.client.ListIssueComments(ctx, s.repo.Name, number, s.config.GitHub.MaxComments)
+	} else {
+		return nil, fmt.Errorf("unknown github item %q", item.SourceItemID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(comments))
+	for _, c := range comments {
+		items = append(items, &Item{
+			SourceItem: database.SourceItem{
+				SourceItemID:    c.ID,
+				Title:           item.Title,
+				Content:         c.Body,
+				Author:          c.Author,
+				URL:             c.URL,
+				Score:           c.Reactions,
+				SourceCreatedAt: c.CreatedAt,
+				ParentItemID:    item.SourceItemID,
+			},
+			Depth: c.Depth,
+			Focus: item.Focus,
+		})
+	}
+	return items, nil
+}
+
+func issueID(repo string, number int) string {
+	return repo + "#" + strconv.Itoa(number)
+}
+
+func discussionID(repo string, number int) string {
+	return repo + "/discussions/" + strconv.Itoa(number)
+}
+
+func parseNumber(id, sep string) (int, bool) {
+	i := strings.LastIndex(id, sep)
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(id[i+len(sep):])
+	return n, err == nil
+}
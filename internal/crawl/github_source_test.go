@@ -0,0 +1,105 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/github"
+)
+
+func TestGitHubListItemsDedupesLabels(t *testing.T) {
+	// Issue 12 carries both labels and is returned by both requests
+	byLabel := map[string][]int{"bug": {12, 14}, "enhancement": {15, 12}}
+	var labels []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		label := r.URL.Query().Get("labels")
+		labels = append(labels, label)
+		fmt.Fprint(w, "[")
+		for i, n := range byLabel[label] {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"number":%d,"title":"Issue %d","html_url":"https://github.com/acme/app/issues/%d","user":{"login":"alice"},"labels":[{"name":%q}],"comments":1,"created_at":"2026-10-01T10:00:00Z"}`, n, n, n, label)
+		}
+		fmt.Fprint(w, "]")
+	}))
+	defer srv.Close()
+
+	s := &githubSource{
+		client: github.NewClient(srv.URL, ""),
+		config: &config.Config{},
+		repo: config.GitHubRepo{
+			Name:   "acme/app",
+			Labels: []string{"bug", "enhancement"},
+			Limit:  10,
+			// Without a token discussions fail, the issues must be kept
+			Discussions: true,
+			Focus:       "products",
+		},
+	}
+
+	items, err := s.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if !slices.Equal(labels, []string{"bug", "enhancement"}) {
+		t.Fatalf("requested labels %v, want one request per label", labels)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.SourceItemID)
+	}
+	if want := []string{"acme/app#12", "acme/app#14", "acme/app#15"}; !slices.Equal(ids, want) {
+		t.Fatalf("items = %v, want %v", ids, want)
+	}
+	if item := items[0]; !item.WithComments || !item.IsSelf || item.Flair != "bug" || item.Focus != "products" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestGitHubFetchCommentsRoutesByID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/app/issues/12/comments":
+			fmt.Fprint(w, `[{"id":501,"body":"+1","html_url":"https://github.com/acme/app/issues/12#issuecomment-501","user":{"login":"bob"},"reactions":{"total_count":2},"created_at":"2026-10-02T10:00:00Z"}]`)
+		case "/graphql":
+			fmt.Fprint(w, `{"data":{"repository":{"discussion":{"comments":{"nodes":[
+  {"id":"DC_1","body":"Love it","url":"u1","createdAt":"2026-10-02T10:00:00Z","author":{"login":"carol"},"reactions":{"totalCount":1},
+   "replies":{"nodes":[{"id":"DC_2","body":"Me too","url":"u2","createdAt":"2026-10-02T11:00:00Z","author":{"login":"dan"},"reactions":{"totalCount":0}}]}}
+]}}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.GitHub.MaxComments = 10
+	s := &githubSource{client: github.NewClient(srv.URL, "tok"), config: cfg, repo: config.GitHubRepo{Name: "acme/app"}}
+
+	issue := &Item{}
+	issue.SourceItemID = issueID("acme/app", 12)
+	comments, err := s.FetchComments(context.Background(), issue)
+	if err != nil {
+		t.Fatalf("FetchComments of issue: %v", err)
+	}
+	if len(comments) != 1 || comments[0].SourceItemID != "501" || comments[0].Score != 2 || comments[0].ParentItemID != issue.SourceItemID {
+		t.Fatalf("unexpected issue comments %+v", comments)
+	}
+
+	discussion := &Item{}
+	discussion.SourceItemID = discussionID("acme/app", 7)
+	comments, err = s.FetchComments(context.Background(), discussion)
+	if err != nil {
+		t.Fatalf("FetchComments of discussion: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Depth != 0 || comments[1].Depth != 1 || comments[1].Author != "dan" {
+		t.Fatalf("unexpected discussion comments %+v", comments)
+	}
+}
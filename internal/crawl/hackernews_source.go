@@ -0,0 +1,107 @@
+package crawl
+
+import (
+	"context"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/hackernews"
+)
+
+// hackerNewsSource crawls one hackernews.lists search. Stories are analyzed
+// together with their comment tree.
+type hackerNewsSource struct {
+	client *hackernews.Client
+	config *config.Config
+	list   config.HackerNewsList
+}
+
+func newHackerNewsSources(cfg *config.Config) ([]Source, error) {
+	client := hackernews.NewClient(cfg.HackerNews.BaseURL)
+
+	sources := make([]Source, 0, len(cfg.HackerNews.Lists))
+	for _, list := range cfg.HackerNews.Lists {
+		sources = append(sources, &hackerNewsSource{client: client, config: cfg, list: list})
+	}
+	return sources, nil
+}
+
+func (s *hackerNewsSource) Name() string {
+	return "hackernews"
+}
+
+func (s *hackerNewsSource) Channel() string {
+	return s.list.Name
+}
+
+func (s *hackerNewsSource) ListItems(ctx context.Context) ([]*Item, error) {
+	stories, err := s.client.Search(ctx, hackernews.SearchOptions{
+		Tags:  s.list.Tags,
+		Query: s.list.Query,
+		Limit: s.config.HackerNews.PostLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(stories))
+	for _, story := range stories {
+		items = append(items, &Item{
+			SourceItem: database.SourceItem{
+				SourceItemID:    story.ID,
+				Title:           story.Title,
+				Content:         story.Text,
+				Author:          story.Author,
+				URL:             hackernews.ItemURL(story.ID),
+				Score:           story.Points,
+				SourceCreatedAt: story.CreatedAt,
+				NumComments:     story.NumComments,
+				IsSelf:          story.URL == "",
+				ExternalURL:     story.URL,
+			},
+			Focus:         analysis.Focus(s.list.Focus),
+			WithComments:  story.NumComments > 0,
+			SharingThread: true,
+		})
+	}
+	return items, nil
+}
+
+func (s *hackerNewsSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	story, err := s.client.FetchItem(ctx, item.SourceItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0 keeps every comment, like the Reddit comment limit
+	limit := s.config.HackerNews.MaxComments
+	var comments []*Item
+	var walk func(children []*hackernews.Item, depth int)
+	walk = func(children []*hackernews.Item, depth int) {
+		for _, c := range children {
+			if limit > 0 && len(comments) >= limit {
+				return
+			}
+			if c.Text != "" {
+				comments = append(comments, &Item{
+					SourceItem: database.SourceItem{
+						SourceItemID:    c.ID,
+						Title:           item.Title,
+						Content:         c.Text,
+						Author:          c.Author,
+						URL:             hackernews.ItemURL(c.ID),
+						SourceCreatedAt: c.CreatedAt,
+						ParentItemID:    item.SourceItemID,
+					},
+					Depth: depth,
+					Focus: item.Focus,
+				})
+			}
+			walk(c.Children, depth+1)
+		}
+	}
+	walk(story.Children, 0)
+
+	return comments, nil
+}
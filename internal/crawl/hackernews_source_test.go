@@ -0,0 +1,117 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/hackernews"
+)
+
+func newTestHackerNewsSource(t *testing.T, maxComments int) *hackerNewsSource {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search_by_date", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../hackernews/testdata/search_by_date.json")
+	})
+	mux.HandleFunc("/items/1001", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../hackernews/testdata/item_1001.json")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{}
+	cfg.HackerNews.PostLimit = 10
+	cfg.HackerNews.MaxComments = maxComments
+	return &hackerNewsSource{
+		client: hackernews.NewClient(srv.URL),
+		config: cfg,
+		list:   config.HackerNewsList{Name: "ask", Tags: "ask_hn", Focus: "problems"},
+	}
+}
+
+func TestHackerNewsListItems(t *testing.T) {
+	s := newTestHackerNewsSource(t, 10)
+
+	items, err := s.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	ask, show := items[0], items[1]
+	if !ask.IsSelf || !ask.WithComments || !ask.SharingThread || ask.Focus != "problems" {
+		t.Fatalf("unexpected ask item %+v", ask)
+	}
+	if ask.URL != "https://news.ycombinator.com/item?id=1001" {
+		t.Fatalf("URL = %q", ask.URL)
+	}
+	if show.IsSelf || show.WithComments || show.ExternalURL != "https://invoices.example.com" {
+		t.Fatalf("unexpected show item %+v", show)
+	}
+}
+
+func TestHackerNewsFetchCommentsWalksTree(t *testing.T) {
+	s := newTestHackerNewsSource(t, 10)
+	story := &Item{Focus: "problems"}
+	story.SourceItemID = "1001"
+	story.Title = "Ask HN: What tool do you wish existed?"
+
+	comments, err := s.FetchComments(context.Background(), story)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+
+	// Deleted (1004, 1006) and dead (1007) comments have no text and are
+	// skipped, the reply under the deleted 1004 is kept.
+	want := []struct {
+		id    string
+		depth int
+	}{{"1002", 0}, {"1003", 1}, {"1005", 1}}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d", len(comments), len(want))
+	}
+	for i, c := range comments {
+		if c.SourceItemID != want[i].id || c.Depth != want[i].depth {
+			t.Fatalf("comment %d = %s at depth %d, want %s at depth %d", i, c.SourceItemID, c.Depth, want[i].id, want[i].depth)
+		}
+		if c.ParentItemID != "1001" || c.Title != story.Title || c.Focus != story.Focus {
+			t.Fatalf("comment %s not linked to its story: %+v", c.SourceItemID, c)
+		}
+		if c.Content == "" {
+			t.Fatalf("comment %s has no content", c.SourceItemID)
+		}
+	}
+}
+
+func TestHackerNewsFetchCommentsCapsCount(t *testing.T) {
+	s := newTestHackerNewsSource(t, 2)
+	story := &Item{}
+	story.SourceItemID = "1001"
+
+	comments, err := s.FetchComments(context.Background(), story)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+}
+
+func TestHackerNewsFetchCommentsWithoutLimit(t *testing.T) {
+	s := newTestHackerNewsSource(t, 0)
+	story := &Item{}
+	story.SourceItemID = "1001"
+
+	comments, err := s.FetchComments(context.Background(), story)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("got %d comments, want all 3", len(comments))
+	}
+}
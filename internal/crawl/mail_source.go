@@ -0,0 +1,99 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/mailbox"
+)
+
+// Threads shorter than this are thank-you notes and auto-replies.
+const minMailThreadLength = 200
+
+// mailSource imports mbox files and Maildir directories. Every thread
+// becomes one item keyed by the Message-IDs of its root and latest message,
+// so a thread that gained replies since the last import is analyzed again.
+type mailSource struct {
+	paths []string
+}
+
+// NewMailSource creates a source reading the given mbox files or Maildir
+// directories.
+func NewMailSource(paths []string) (Source, error) {
+	return &mailSource{paths: paths}, nil
+}
+
+func (s *mailSource) Name() string {
+	return "email"
+}
+
+func (s *mailSource) Channel() string {
+	return strings.Join(s.paths, ", ")
+}
+
+func (s *mailSource) ListItems(ctx context.Context) ([]*Item, error) {
+	var items []*Item
+	for _, path := range s.paths {
+		messages, err := mailbox.Load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		channel := filepath.Base(filepath.Clean(path))
+		for _, t := range mailbox.Group(messages) {
+			items = append(items, mailThreadItem(channel, t))
+		}
+	}
+	return items, nil
+}
+
+// FetchComments is not supported, replies are already part of the thread.
+func (s *mailSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	return nil, fmt.Errorf("email threads have no separate comments")
+}
+
+func mailThreadItem(channel string, t *mailbox.Thread) *Item {
+	var b strings.Builder
+	for _, m := range t.Messages {
+		if m.Body == "" {
+			continue
+		}
+		entry := fmt.Sprintf("From: %s\n%s\n\n", m.From, m.Body)
+		if b.Len() > 0 && b.Len()+len(entry) > maxCommentContext {
+			break
+		}
+		b.WriteString(entry)
+	}
+
+	first, last := t.Messages[0], t.Messages[len(t.Messages)-1]
+	item := &Item{
+		SourceItem: database.SourceItem{
+			Channel:         channel,
+			SourceItemID:    mailThreadID(t.ID, last.ID),
+			Title:           t.Subject(),
+			Content:         strings.TrimSpace(b.String()),
+			Author:          first.From,
+			SourceCreatedAt: first.Date,
+			NumComments:     len(t.Messages) - 1,
+			IsSelf:          true,
+		},
+	}
+	if item.Title == "" {
+		item.Title = "(no subject)"
+	}
+	if len(item.Content) < minMailThreadLength {
+		item.Skip = "too short"
+	}
+	return item
+}
+
+// mailThreadID keys a thread by its root and its latest message.
+func mailThreadID(rootID, lastID string) string {
+	if rootID == lastID {
+		return rootID
+	}
+	return rootID + " " + lastID
+}
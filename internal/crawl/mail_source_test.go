@@ -0,0 +1,84 @@
+package crawl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mailRoot = `From alice@example.com Mon Oct  5 10:00:00 2026
+Message-ID: <root@example.com>
+From: Alice <alice@example.com>
+Subject: Looking for a better invoicing tool
+Date: Mon, 05 Oct 2026 10:00:00 +0000
+
+` + "Every month I spend hours copying hours from my time tracker into invoices, and nothing I tried does it automatically. " +
+	"I would happily pay for a tool that reads the tracker and drafts the invoice for me.\n\n"
+
+const mailReply = `From bob@example.com Tue Oct  6 10:00:00 2026
+Message-ID: <reply@example.com>
+In-Reply-To: <root@example.com>
+From: Bob <bob@example.com>
+Subject: Re: Looking for a better invoicing tool
+Date: Tue, 06 Oct 2026 10:00:00 +0000
+
+Same here, my accountant keeps asking for CSV exports.
+
+`
+
+func listMailItems(t *testing.T, path string) []*Item {
+	t.Helper()
+	src, err := NewMailSource([]string{path})
+	if err != nil {
+		t.Fatalf("NewMailSource: %v", err)
+	}
+	items, err := src.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	return items
+}
+
+func TestMailThreadIsImportedAgainWhenItGrows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inbox.mbox")
+	if err := os.WriteFile(path, []byte(mailRoot), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := listMailItems(t, path)[0]
+	if first.SourceItemID != "root@example.com" || first.NumComments != 0 || first.Channel != "inbox.mbox" {
+		t.Fatalf("unexpected item %+v", first)
+	}
+
+	if err := os.WriteFile(path, []byte(mailRoot+mailReply), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	grown := listMailItems(t, path)[0]
+	if grown.SourceItemID == first.SourceItemID {
+		t.Fatalf("thread kept the ID %q after gaining a reply", grown.SourceItemID)
+	}
+	if grown.SourceItemID != "root@example.com reply@example.com" || grown.NumComments != 1 {
+		t.Fatalf("unexpected item %+v", grown)
+	}
+	if !strings.Contains(grown.Content, "From: Bob\nSame here") || grown.Title != "Looking for a better invoicing tool" {
+		t.Fatalf("reply missing from the thread: %q", grown.Content)
+	}
+}
+
+func TestMailThreadTooShortIsSkipped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inbox.mbox")
+	if err := os.WriteFile(path, []byte(mailReply), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	item := listMailItems(t, path)[0]
+	if item.Skip != "too short" {
+		t.Fatalf("Skip = %q, want a short thread to be skipped", item.Skip)
+	}
+}
@@ -0,0 +1,155 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/htmltext"
+	"github.com/letieu/idea-extractor/internal/mastodon"
+)
+
+// mastodonSource crawls one mastodon.timelines entry. Replies found in the
+// timeline are traced back to their root, and every root is analyzed with
+// its reply thread. Statuses are keyed by their ActivityPub URI, which is
+// the same on every instance.
+type mastodonSource struct {
+	client   *mastodon.Client
+	config   *config.Config
+	timeline config.MastodonTimeline
+
+	// Instance-local status IDs by URI, the thread of a status can only be
+	// fetched by its local ID
+	localIDs map[string]string
+}
+
+func newMastodonSources(cfg *config.Config) ([]Source, error) {
+	clients := map[string]*mastodon.Client{}
+
+	sources := make([]Source, 0, len(cfg.Mastodon.Timelines))
+	for _, tl := range cfg.Mastodon.Timelines {
+		key := tl.Instance + "\x00" + tl.Token
+		if clients[key] == nil {
+			clients[key] = mastodon.NewClient(tl.Instance, tl.Token)
+		}
+		sources = append(sources, &mastodonSource{client: clients[key], config: cfg, timeline: tl, localIDs: map[string]string{}})
+	}
+	return sources, nil
+}
+
+func (s *mastodonSource) Name() string {
+	return "mastodon"
+}
+
+func (s *mastodonSource) Channel() string {
+	return s.timeline.Name
+}
+
+func (s *mastodonSource) ListItems(ctx context.Context) ([]*Item, error) {
+	var statuses []*mastodon.Status
+	var err error
+	if s.timeline.Hashtag != "" {
+		statuses, err = s.client.HashtagTimeline(ctx, s.timeline.Hashtag, s.timeline.Limit)
+	} else {
+		statuses, err = s.client.AccountStatuses(ctx, s.timeline.Account, s.timeline.Limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	items := make([]*Item, 0, len(statuses))
+	for _, st := range statuses {
+		if st.InReplyToID != "" {
+			root, err := s.root(ctx, st)
+			if err != nil {
+				log.Printf("Failed to fetch the thread of %s: %v", st.URL, err)
+				continue
+			}
+			st = root
+		}
+		if seen[st.URI] {
+			continue
+		}
+		seen[st.URI] = true
+		s.localIDs[st.URI] = st.ID
+		items = append(items, s.statusItem(st))
+	}
+	return items, nil
+}
+
+// FetchComments returns the replies to a root status, with their depth in
+// the thread.
+func (s *mastodonSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	id, ok := s.localIDs[item.SourceItemID]
+	if !ok {
+		return nil, fmt.Errorf("status %s was not listed by this source", item.SourceItemID)
+	}
+	thread, err := s.client.Context(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	depths := map[string]int{id: -1}
+	var comments []*Item
+	for _, st := range thread.Descendants {
+		if len(comments) >= s.config.Mastodon.MaxReplies {
+			break
+		}
+		depth := depths[st.InReplyToID] + 1
+		depths[st.ID] = depth
+
+		comments = append(comments, &Item{
+			SourceItem: database.SourceItem{
+				Channel:         item.Channel,
+				SourceItemID:    st.URI,
+				Title:           item.Title,
+				Content:         st.Content,
+				Author:          st.Author,
+				URL:             st.URL,
+				Score:           st.Favourites,
+				SourceCreatedAt: st.CreatedAt,
+				ParentItemID:    item.SourceItemID,
+			},
+			Depth: depth,
+			Focus: item.Focus,
+		})
+	}
+	return comments, nil
+}
+
+// root returns the first status of the thread a reply belongs to.
+func (s *mastodonSource) root(ctx context.Context, st *mastodon.Status) (*mastodon.Status, error) {
+	thread, err := s.client.Context(ctx, st.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(thread.Ancestors) == 0 {
+		return st, nil
+	}
+	return thread.Ancestors[0], nil
+}
+
+func (s *mastodonSource) statusItem(st *mastodon.Status) *Item {
+	return &Item{
+		SourceItem: database.SourceItem{
+			Channel:         s.timeline.Name,
+			SourceItemID:    st.URI,
+			Title:           htmltext.Headline(st.Content, 100),
+			Content:         st.Content,
+			Author:          st.Author,
+			URL:             st.URL,
+			Score:           st.Favourites + st.Reblogs,
+			SourceCreatedAt: st.CreatedAt,
+			NumComments:     st.Replies,
+			NSFW:            st.Sensitive,
+			IsSelf:          st.CardURL == "",
+			ExternalURL:     st.CardURL,
+		},
+		Focus:        analysis.Focus(s.timeline.Focus),
+		WithComments: st.Replies > 0,
+	}
+}
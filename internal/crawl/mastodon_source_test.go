@@ -0,0 +1,160 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/mastodon"
+)
+
+// stubStatus is the part of a Mastodon status the source reads.
+type stubStatus struct {
+	ID          string  `json:"id"`
+	URI         string  `json:"uri"`
+	InReplyToID *string `json:"in_reply_to_id"`
+	Account     struct {
+		Acct string `json:"acct"`
+	} `json:"account"`
+	Content      string `json:"content"`
+	RepliesCount int    `json:"replies_count"`
+}
+
+func newStubStatus(id, replyTo string, replies int) stubStatus {
+	s := stubStatus{ID: id, URI: "https://stub.social/statuses/" + id, Content: "<p>status " + id + "</p>", RepliesCount: replies}
+	s.Account.Acct = "user" + id
+	if replyTo != "" {
+		s.InReplyToID = &replyTo
+	}
+	return s
+}
+
+// newStubMastodon serves a hashtag timeline holding root 1, reply 5 in the
+// thread of 1, and reply 7 in the thread of root 2. Root 2 has a reply tree
+// three levels deep.
+func newStubMastodon(t *testing.T) *httptest.Server {
+	t.Helper()
+	contexts := map[string]any{
+		"5": map[string]any{"ancestors": []stubStatus{newStubStatus("1", "", 1)}, "descendants": []stubStatus{}},
+		"7": map[string]any{"ancestors": []stubStatus{newStubStatus("2", "", 4), newStubStatus("6", "2", 1)}, "descendants": []stubStatus{}},
+		"2": map[string]any{"ancestors": []stubStatus{}, "descendants": []stubStatus{
+			newStubStatus("6", "2", 1), newStubStatus("7", "6", 1), newStubStatus("8", "7", 0), newStubStatus("9", "2", 0),
+		}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/timelines/tag/buildinpublic", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]stubStatus{newStubStatus("1", "", 1), newStubStatus("5", "1", 0), newStubStatus("7", "6", 1)})
+	})
+	mux.HandleFunc("/api/v1/statuses/{id}/context", func(w http.ResponseWriter, r *http.Request) {
+		thread, ok := contexts[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(thread)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestMastodonSource(t *testing.T, maxReplies int) *mastodonSource {
+	t.Helper()
+	srv := newStubMastodon(t)
+	cfg := &config.Config{}
+	cfg.Mastodon.MaxReplies = maxReplies
+	return &mastodonSource{
+		client:   mastodon.NewClient(srv.URL, ""),
+		config:   cfg,
+		timeline: config.MastodonTimeline{Name: "bip", Hashtag: "buildinpublic", Focus: "products"},
+		localIDs: map[string]string{},
+	}
+}
+
+func TestMastodonListItemsResolvesReplies(t *testing.T) {
+	s := newTestMastodonSource(t, 10)
+
+	items, err := s.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	// Reply 5 resolves to root 1, which is already listed, and reply 7 to
+	// root 2 through its ancestors
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.SourceItemID)
+	}
+	want := []string{"https://stub.social/statuses/1", "https://stub.social/statuses/2"}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("items = %v, want %v", ids, want)
+	}
+	if s.localIDs[want[1]] != "2" {
+		t.Fatalf("local ID of root 2 = %q", s.localIDs[want[1]])
+	}
+	if item := items[1]; item.Channel != "bip" || item.Title != "status 2" || !item.WithComments || item.Focus != "products" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestMastodonFetchCommentsDepth(t *testing.T) {
+	s := newTestMastodonSource(t, 10)
+	if _, err := s.ListItems(context.Background()); err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	root := &Item{}
+	root.SourceItemID = "https://stub.social/statuses/2"
+	root.Channel = "bip"
+
+	comments, err := s.FetchComments(context.Background(), root)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+
+	want := map[string]int{"6": 0, "7": 1, "8": 2, "9": 0}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d", len(comments), len(want))
+	}
+	for _, c := range comments {
+		id := strings.TrimPrefix(c.SourceItemID, "https://stub.social/statuses/")
+		if c.Depth != want[id] {
+			t.Fatalf("reply %s at depth %d, want %d", id, c.Depth, want[id])
+		}
+		if c.ParentItemID != root.SourceItemID || c.Channel != "bip" {
+			t.Fatalf("reply %s not linked to its root: %+v", id, c)
+		}
+	}
+}
+
+func TestMastodonFetchCommentsCapsReplies(t *testing.T) {
+	s := newTestMastodonSource(t, 2)
+	if _, err := s.ListItems(context.Background()); err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	root := &Item{}
+	root.SourceItemID = "https://stub.social/statuses/2"
+
+	comments, err := s.FetchComments(context.Background(), root)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+}
+
+func TestMastodonFetchCommentsUnknownStatus(t *testing.T) {
+	s := newTestMastodonSource(t, 10)
+	item := &Item{}
+	item.SourceItemID = "https://stub.social/statuses/404"
+
+	if _, err := s.FetchComments(context.Background(), item); err == nil {
+		t.Fatal("expected an error for a status the source did not list")
+	}
+}
@@ -0,0 +1,151 @@
+package crawl
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// ItemChecker tells whether an item is already stored, *database.DB is one.
+type ItemChecker interface {
+	SourceItemExists(source string, sourceItemID string) (bool, error)
+}
+
+// MemoryStore is the CrawlerStore of dry runs: items and runs are kept in
+// memory and never reach the database.
+type MemoryStore struct {
+	known ItemChecker
+
+	mu    sync.Mutex
+	items []*database.SourceItem
+	runs  []*database.CrawlRun
+}
+
+// NewMemoryStore creates an empty store. Items known to known, when it is
+// not nil, count as stored too, e.g. to skip what the database already has
+// without writing to it.
+func NewMemoryStore(known ItemChecker) *MemoryStore {
+	return &MemoryStore{known: known}
+}
+
+func (s *MemoryStore) SourceItemExists(source string, sourceItemID string) (bool, error) {
+	s.mu.Lock()
+	for _, item := range s.items {
+		if item.Source == source && item.SourceItemID == sourceItemID {
+			s.mu.Unlock()
+			return true, nil
+		}
+	}
+	s.mu.Unlock()
+
+	if s.known != nil {
+		return s.known.SourceItemExists(source, sourceItemID)
+	}
+	return false, nil
+}
+
+func (s *MemoryStore) CreateSourceItem(item *database.SourceItem, analysisResult string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *item
+	stored.ID = len(s.items) + 1
+	stored.AnalysisResult = analysisResult
+	stored.CreatedAt = time.Now()
+	if stored.Status == "" {
+		stored.Status = database.StatusAnalyzed
+	}
+	s.items = append(s.items, &stored)
+	return nil
+}
+
+func (s *MemoryStore) UpdateSourceItemAnalysis(item *database.SourceItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.items {
+		if stored.ID == item.ID {
+			stored.Status = item.Status
+			stored.AnalysisResult = item.AnalysisResult
+			stored.Attempts = item.Attempts
+			stored.LastError = item.LastError
+			stored.NextRetryAt = item.NextRetryAt
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetRetryableSourceItems(limit int) ([]*database.SourceItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var items []*database.SourceItem
+	for _, stored := range s.items {
+		if stored.Status == database.StatusFailed && !stored.NextRetryAt.After(now) {
+			item := *stored
+			items = append(items, &item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].NextRetryAt.Before(items[j].NextRetryAt)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) CreateCrawlRun(run *database.CrawlRun) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *run
+	stored.ID = len(s.runs) + 1
+	s.runs = append(s.runs, &stored)
+	return stored.ID, nil
+}
+
+func (s *MemoryStore) UpdateCrawlRun(run *database.CrawlRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run.ID < 1 || run.ID > len(s.runs) {
+		return nil
+	}
+	stored := *run
+	s.runs[run.ID-1] = &stored
+	return nil
+}
+
+// SourceItems returns a copy of the stored items, oldest first.
+func (s *MemoryStore) SourceItems() []database.SourceItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]database.SourceItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, *item)
+	}
+	return items
+}
+
+// CrawlRuns returns a copy of the stored runs, oldest first.
+func (s *MemoryStore) CrawlRuns() []database.CrawlRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]database.CrawlRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, *run)
+	}
+	return runs
+}
+
+// Close closes known when it can be closed, the stored items are kept.
+func (s *MemoryStore) Close() error {
+	if closer, ok := s.known.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package crawl
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// Items fetched ahead of the analysis workers. Fetching only waits for the
+// workers once this many items are queued.
+const queueSize = 256
+
+// job is an item waiting for the analyzer. Comments of sharing threads are
+// only searched for products.
+type job struct {
+	item         *Item
+	productsOnly bool
+	run          *runStats
+}
+
+// result is the outcome of a job: an analysis to store with its status, or
+// an error to retry later.
+type result struct {
+	item     *Item
+	run      *runStats
+	analysis *analysis.AnalysisResult
+	status   string
+	err      error
+}
+
+// pipeline separates fetching from analysis. Sources are fetched by the
+// caller, which queues items for a pool of analysis workers, and a single
+// writer stores the results so the store is never written concurrently.
+type pipeline struct {
+	crawler *Crawler
+	jobs    chan job
+	results chan result
+	workers sync.WaitGroup
+	written chan struct{}
+
+	// Items queued in this run, only touched by the fetching goroutine
+	queued map[string]bool
+
+	// Runs of the crawled channels, items are counted in the current one
+	runs    []*runStats
+	current *runStats
+}
+
+func (c *Crawler) startPipeline(ctx context.Context) *pipeline {
+	p := &pipeline{
+		crawler: c,
+		jobs:    make(chan job, queueSize),
+		results: make(chan result),
+		written: make(chan struct{}),
+		queued:  map[string]bool{},
+	}
+
+	for i := 0; i < c.config.Crawler.Concurrency; i++ {
+		p.workers.Add(1)
+		go p.work(ctx)
+	}
+	go p.write()
+
+	return p
+}
+
+// submit queues an item for analysis unless it was already queued in this
+// run, e.g. by a story listed in two Hacker News searches.
+func (p *pipeline) submit(ctx context.Context, j job) {
+	key := j.item.Source + "\x00" + j.item.SourceItemID
+	if p.queued[key] {
+		return
+	}
+	p.queued[key] = true
+
+	if !p.crawler.reserveLLMCall() {
+		log.Printf("LLM call limit reached, not analyzing: %s", j.item.SourceItemID)
+		return
+	}
+
+	// Recorded so a failed analysis can be run again the same way. Review
+	// batches come with their mode set by the review source.
+	if j.productsOnly {
+		j.item.AnalysisMode = "products"
+	}
+	j.item.AnalysisFocus = string(j.item.Focus)
+	j.run = p.current
+
+	select {
+	case p.jobs <- j:
+	case <-ctx.Done():
+	}
+}
+
+// wait lets the workers finish the queued items and the writer store their
+// results, then stores the runs. Once ctx is cancelled the remaining items
+// are dropped, results already computed are still stored.
+func (p *pipeline) wait() {
+	close(p.jobs)
+	p.workers.Wait()
+	close(p.results)
+	<-p.written
+	p.finishRuns()
+}
+
+func (p *pipeline) work(ctx context.Context) {
+	defer p.workers.Done()
+	for j := range p.jobs {
+		if ctx.Err() != nil {
+			continue
+		}
+		res, status, err := p.crawler.analyze(ctx, j)
+		p.results <- result{item: j.item, run: j.run, analysis: res, status: status, err: err}
+	}
+}
+
+func (p *pipeline) write() {
+	defer close(p.written)
+	for r := range p.results {
+		r.run.addResult(r)
+		if p.crawler.dryRun != nil {
+			p.crawler.writeDryRun(r)
+		}
+		if r.err != nil {
+			p.crawler.saveFailure(r.item, r.err)
+		} else {
+			p.crawler.saveItem(r.item, r.analysis, r.status)
+		}
+	}
+}
+
+// analyze runs the analyzer on a queued item and returns the result with
+// the status to store it with.
+func (c *Crawler) analyze(ctx context.Context, j job) (*analysis.AnalysisResult, string, error) {
+	item := j.item
+
+	if j.productsOnly {
+		analysisResult, err := c.analyzer.ExtractProducts(ctx, item.Content)
+		if err != nil {
+			log.Printf("Failed to extract products from comment %s: %v", item.SourceItemID, err)
+			return nil, "", err
+		}
+		if len(analysisResult.Products) == 0 {
+			log.Printf("No product in comment, recording it as empty: %s", item.SourceItemID)
+			return analysisResult, database.StatusEmpty, nil
+		}
+		return analysisResult, database.StatusAnalyzed, nil
+	}
+
+	var analysisResult *analysis.AnalysisResult
+	var err error
+	if item.AnalysisMode == "review" {
+		// Review batches are stored with their product as the channel
+		analysisResult, err = c.analyzer.ExtractReviewProblems(ctx, item.Channel, item.Content)
+	} else {
+		analysisResult, err = c.analyzer.ExtractAnalysisWithFocus(ctx, item.Title+"\n"+item.Content, item.Focus)
+	}
+	if err != nil {
+		log.Printf("Failed to extract analysis from item: %v", err)
+		return nil, "", err
+	}
+
+	if analysisResult.IsMeta {
+		log.Printf("Item is meta, recording it: %s", item.Title)
+		return analysisResult, database.StatusMeta, nil
+	}
+
+	isEmpty := analysisResult.Idea.Score == 0 && analysisResult.Problem.Score == 0 && len(analysisResult.Products) == 0
+	if isEmpty {
+		log.Printf("Empty item, recording it: %s", item.Title)
+		return analysisResult, database.StatusEmpty, nil
+	}
+
+	return analysisResult, database.StatusAnalyzed, nil
+}
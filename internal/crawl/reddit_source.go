@@ -0,0 +1,213 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/reddit"
+)
+
+// Comments fetched per Reddit thread, replies included.
+const maxRedditComments = 500
+
+// redditSource crawls a single subreddit. All subreddits share one client,
+// and so one rate limiter.
+type redditSource struct {
+	client    *reddit.RedditClient
+	config    *config.Config
+	subreddit config.Subreddit
+}
+
+func newRedditSources(cfg *config.Config) ([]Source, error) {
+	client, err := reddit.NewClient(reddit.Options{
+		ClientID:     cfg.Reddit.ClientID,
+		ClientSecret: cfg.Reddit.ClientSecret,
+		UserAgent:    cfg.Reddit.UserAgent,
+		Anonymous:    cfg.Reddit.Mode == "anonymous",
+		MinInterval:  time.Duration(cfg.Crawler.RateLimitSecs) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]Source, 0, len(cfg.Crawler.Subreddits))
+	for _, sub := range cfg.Crawler.Subreddits {
+		sources = append(sources, &redditSource{client: client, config: cfg, subreddit: sub})
+	}
+	return sources, nil
+}
+
+func (s *redditSource) Name() string {
+	return "reddit"
+}
+
+func (s *redditSource) Channel() string {
+	return s.subreddit.Name
+}
+
+func (s *redditSource) ListItems(ctx context.Context) ([]*Item, error) {
+	limit := s.subreddit.Limit
+	if limit == 0 {
+		limit = s.config.Crawler.PostLimit
+	}
+
+	posts, err := s.client.FetchPosts(ctx, s.subreddit.Name, reddit.ListingOptions{
+		Sort:  s.subreddit.Listing,
+		Time:  s.subreddit.Time,
+		Query: s.subreddit.Query,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, s.postItem(post))
+	}
+	return items, nil
+}
+
+func (s *redditSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	comments, err := s.client.FetchComments(ctx, s.subreddit.Name, item.SourceItemID, reddit.CommentOptions{
+		MaxComments: maxRedditComments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(comments))
+	for _, comment := range comments {
+		items = append(items, &Item{
+			SourceItem: database.SourceItem{
+				SourceItemID:    comment.ID,
+				Channel:         s.subreddit.Name,
+				Title:           item.Title,
+				Content:         comment.Content,
+				Author:          comment.Author,
+				URL:             comment.URL,
+				Score:           comment.Score,
+				SourceCreatedAt: comment.CreatedAt,
+				ParentItemID:    item.SourceItemID,
+			},
+			Depth: comment.Depth,
+			Focus: item.Focus,
+		})
+	}
+	return items, nil
+}
+
+// backfill walks the new listing back in time, calling fn for each post
+// until it reaches one created before until, or the post with ID untilID.
+func (s *redditSource) backfill(ctx context.Context, until time.Time, untilID string, fn func(*Item)) error {
+	untilID = strings.TrimPrefix(untilID, "t3_")
+
+	opts := reddit.ListingOptions{}
+	for {
+		listing, err := s.client.FetchListing(ctx, s.subreddit.Name, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, post := range listing.Posts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if untilID != "" && post.ID == untilID {
+				return nil
+			}
+			if !until.IsZero() && post.CreatedAt.Before(until) {
+				return nil
+			}
+			fn(s.postItem(post))
+		}
+
+		if listing.After == "" {
+			return nil
+		}
+		opts.After = listing.After
+	}
+}
+
+func (s *redditSource) postItem(post *reddit.Post) *Item {
+	return &Item{
+		SourceItem: database.SourceItem{
+			SourceItemID:    post.ID,
+			Channel:         s.subreddit.Name,
+			Title:           post.Title,
+			Content:         post.Content,
+			Author:          post.Author,
+			URL:             post.URL,
+			Score:           post.Score,
+			SourceCreatedAt: post.CreatedAt,
+
+			NumComments:          post.NumComments,
+			UpvoteRatio:          post.UpvoteRatio,
+			Flair:                post.Flair,
+			NSFW:                 post.NSFW,
+			IsSelf:               post.IsSelf,
+			Stickied:             post.Stickied,
+			EditedAt:             post.EditedAt,
+			ExternalURL:          post.ExternalURL,
+			SubredditSubscribers: post.SubredditSubscribers,
+		},
+		Focus:         analysis.Focus(s.subreddit.Focus),
+		Skip:          s.skipReason(post),
+		SharingThread: true,
+	}
+}
+
+// skipReason applies the crawler and subreddit post filters. It returns why
+// the post should be skipped, or an empty string to analyze it.
+func (s *redditSource) skipReason(post *reddit.Post) string {
+	crawler := s.config.Crawler
+	rules := s.subreddit
+
+	switch {
+	case crawler.SkipStickied && post.Stickied:
+		return "stickied"
+	case crawler.SkipNSFW && post.NSFW:
+		return "NSFW"
+	case post.NumComments < crawler.MinComments:
+		return fmt.Sprintf("below %d comments", crawler.MinComments)
+	case post.Score < rules.MinScore:
+		return fmt.Sprintf("below score %d", rules.MinScore)
+	case len(rules.FlairAllow) > 0 && !containsFold(rules.FlairAllow, post.Flair):
+		return fmt.Sprintf("flaired %q, not allowed", post.Flair)
+	case containsFold(rules.FlairDeny, post.Flair):
+		return fmt.Sprintf("flaired %q, denied", post.Flair)
+	}
+
+	text := post.Title + "\n" + post.Content
+	if len(rules.Include) > 0 && !matchAny(rules.Include, text) {
+		return "not matching include patterns"
+	}
+	if matchAny(rules.Exclude, text) {
+		return "matching exclude patterns"
+	}
+	return ""
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/reviews"
+)
+
+// Reviews of a product are analyzed in batches, so a problem shared by
+// many reviewers stands out without blowing up the prompt.
+const (
+	maxReviewsPerItem = 30
+	maxReviewContext  = 12000
+)
+
+// ReviewStore records which reviews were already imported in a batch,
+// *database.DB is one.
+type ReviewStore interface {
+	GetImportedReviewIDs(product string) (map[string]bool, error)
+	CreateImportedReviews(product, sourceItemID string, reviewIDs []string) error
+}
+
+// reviewSource imports app store and review-site dumps. Reviews are grouped
+// by product and every batch becomes one item analyzed in review mode.
+type reviewSource struct {
+	paths     []string
+	maxRating float64
+	store     ReviewStore // nil batches every review
+}
+
+// NewReviewSource creates a source reading CSV or JSON review dumps. Only
+// reviews rated maxRating or lower are kept, 0 keeps all of them. Reviews
+// recorded in store are left out, so importing a dump again only batches
+// the reviews it gained.
+func NewReviewSource(paths []string, maxRating float64, store ReviewStore) (Source, error) {
+	return &reviewSource{paths: paths, maxRating: maxRating, store: store}, nil
+}
+
+func (s *reviewSource) Name() string {
+	return "reviews"
+}
+
+func (s *reviewSource) Channel() string {
+	return strings.Join(s.paths, ", ")
+}
+
+func (s *reviewSource) ListItems(ctx context.Context) ([]*Item, error) {
+	var all []*reviews.Review
+	for _, path := range s.paths {
+		loaded, err := reviews.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range loaded {
+			// Unrated reviews are kept, the analyzer ignores praise
+			if s.maxRating > 0 && r.Rating > s.maxRating {
+				continue
+			}
+			all = append(all, r)
+		}
+	}
+
+	var items []*Item
+	for _, group := range reviews.GroupByProduct(all) {
+		group, err := s.newReviews(group)
+		if err != nil {
+			return nil, err
+		}
+		for len(group) > 0 {
+			batch, text := reviewBatch(group)
+			group = group[len(batch):]
+			items = append(items, s.reviewItem(batch, text))
+		}
+	}
+	return items, nil
+}
+
+// newReviews leaves out the reviews of a product already stored in a batch
+// by an earlier import.
+func (s *reviewSource) newReviews(group []*reviews.Review) ([]*reviews.Review, error) {
+	if s.store == nil {
+		return group, nil
+	}
+	imported, err := s.store.GetImportedReviewIDs(group[0].Product)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get imported reviews: %w", err)
+	}
+
+	fresh := group[:0]
+	for _, r := range group {
+		if !imported[r.ID] {
+			fresh = append(fresh, r)
+		}
+	}
+	return fresh, nil
+}
+
+// FetchComments is not supported, reviews have no replies worth analyzing.
+func (s *reviewSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	return nil, fmt.Errorf("reviews have no comments")
+}
+
+// reviewBatch takes reviews from the front of group until the batch is
+// full and returns them with their text.
+func reviewBatch(group []*reviews.Review) ([]*reviews.Review, string) {
+	var b strings.Builder
+	n := 0
+	for _, r := range group {
+		var entry strings.Builder
+		entry.WriteString("- ")
+		if r.Rating > 0 {
+			fmt.Fprintf(&entry, "[%g stars] ", r.Rating)
+		}
+		if r.Title != "" {
+			entry.WriteString(r.Title + ": ")
+		}
+		entry.WriteString(strings.Join(strings.Fields(r.Text), " "))
+		entry.WriteString("\n")
+
+		if n > 0 && (n == maxReviewsPerItem || b.Len()+entry.Len() > maxReviewContext) {
+			break
+		}
+		b.WriteString(entry.String())
+		n++
+	}
+	return group[:n], b.String()
+}
+
+// reviewItem keys the batch by its product and first and last review. The
+// reviews it holds are recorded once the item is stored.
+func (s *reviewSource) reviewItem(batch []*reviews.Review, text string) *Item {
+	first, last := batch[0], batch[len(batch)-1]
+	ids := make([]string, 0, len(batch))
+	for _, r := range batch {
+		ids = append(ids, r.ID)
+	}
+	item := &Item{
+		SourceItem: database.SourceItem{
+			Channel:         first.Product,
+			SourceItemID:    fmt.Sprintf("%s:%s-%s", strings.ToLower(first.Product), first.ID, last.ID),
+			Title:           fmt.Sprintf("%d reviews of %s", len(batch), first.Product),
+			Content:         text,
+			Author:          first.Author,
+			SourceCreatedAt: first.Date,
+			NumComments:     len(batch),
+			IsSelf:          true,
+			AnalysisMode:    "review",
+		},
+	}
+	if s.store != nil {
+		item.onSaved = func() error {
+			return s.store.CreateImportedReviews(first.Product, item.SourceItemID, ids)
+		}
+	}
+	return item
+}
@@ -0,0 +1,105 @@
+package crawl
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// runStats counts what a crawl of one source channel found. It is updated
+// by the fetching goroutine and the writer, and stored once the pipeline is
+// done so each run is a single row in crawl_runs.
+type runStats struct {
+	mu  sync.Mutex
+	run database.CrawlRun
+}
+
+// startRun stores a new run and makes it the one the next submitted items
+// are counted in.
+func (p *pipeline) startRun(source, channel string) *runStats {
+	r := &runStats{run: database.CrawlRun{
+		Source:    source,
+		Channel:   channel,
+		StartedAt: time.Now(),
+	}}
+	id, err := p.crawler.db.CreateCrawlRun(&r.run)
+	if err != nil {
+		log.Printf("Failed to save crawl run: %v", err)
+	}
+	r.run.ID = id
+
+	p.current = r
+	p.runs = append(p.runs, r)
+	return r
+}
+
+// add updates the counts of a run. The run ends with the last update, which
+// is the last result written for it.
+func (r *runStats) add(update func(run *database.CrawlRun)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(&r.run)
+	r.run.FinishedAt = time.Now()
+}
+
+// addResult counts the outcome of analyzing an item and the LLM usage
+// behind it.
+func (r *runStats) addResult(res result) {
+	r.add(func(run *database.CrawlRun) {
+		if res.err != nil {
+			run.Failed++
+			// Counted when the LLM was called before the analysis failed
+			var usageErr *analysis.UsageError
+			if errors.As(res.err, &usageErr) {
+				addUsage(run, usageErr.Usage)
+			}
+			return
+		}
+		switch res.status {
+		case database.StatusAnalyzed:
+			run.Analyzed++
+		case database.StatusMeta:
+			run.Meta++
+		case database.StatusEmpty:
+			run.Empty++
+		}
+		addUsage(run, res.analysis.Usage)
+	})
+}
+
+func addUsage(run *database.CrawlRun, usage analysis.Usage) {
+	run.LLMCalls++
+	run.PromptTokens += usage.PromptTokens
+	run.CompletionTokens += usage.CompletionTokens
+	run.LLMLatency += usage.Latency
+}
+
+// finishRuns stores the counts of every run of the pipeline.
+func (p *pipeline) finishRuns() {
+	for _, r := range p.runs {
+		r.mu.Lock()
+		run := r.run
+		r.mu.Unlock()
+
+		if run.FinishedAt.IsZero() {
+			run.FinishedAt = time.Now()
+		}
+		log.Printf("Run of %s %s: %d fetched, %d existing, %d filtered, %d analyzed, %d meta, %d empty, %d failed, %d tokens",
+			run.Source, run.Channel, run.Fetched, run.SkippedExisting, run.SkippedFiltered,
+			run.Analyzed, run.Meta, run.Empty, run.Failed, run.PromptTokens+run.CompletionTokens)
+
+		if run.ID == 0 {
+			continue
+		}
+		if err := p.crawler.db.UpdateCrawlRun(&run); err != nil {
+			log.Printf("Failed to save crawl run: %v", err)
+		}
+	}
+}
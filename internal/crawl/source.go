@@ -0,0 +1,96 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+// Source is a place the crawler pulls items from, such as one subreddit.
+type Source interface {
+	// Name is stored in the source column of source_items, e.g. "reddit".
+	Name() string
+	// Channel tells instances of the same source apart, e.g. the subreddit.
+	Channel() string
+	// ListItems returns the latest items of the source.
+	ListItems(ctx context.Context) ([]*Item, error)
+	// FetchComments returns the replies to an item, parents before children.
+	FetchComments(ctx context.Context, item *Item) ([]*Item, error)
+}
+
+// Item is a piece of content from a Source waiting to be analyzed. The
+// embedded SourceItem is what gets stored once the analysis is done.
+type Item struct {
+	database.SourceItem
+
+	Depth int            // Reply depth of comments, 0 for top-level ones
+	Focus analysis.Focus // Analyzer hint from the source configuration
+	Skip  string         // Why the source filters rejected the item, empty to analyze it
+
+	// WithComments makes the crawler fetch the comments of the item and
+	// analyze and store them as part of its content.
+	WithComments bool
+
+	// SharingThread lets the crawler treat the item as a "share what you're
+	// building" thread when its title matches crawler.sharing_keywords. Only
+	// sources whose top-level replies are pitches set it, Reddit and HN.
+	SharingThread bool
+
+	// onSaved runs once the item is stored, for sources that keep a record
+	// of what their items were built from.
+	onSaved func() error
+}
+
+// Comment context added to an item is capped to keep prompts small.
+const maxCommentContext = 12000
+
+// sourceBuilders creates the sources that can be enabled in
+// crawler.sources. A builder may return several sources, one per channel.
+var sourceBuilders = map[string]func(cfg *config.Config) ([]Source, error){
+	"reddit":        newRedditSources,
+	"hackernews":    newHackerNewsSources,
+	"feed":          newFeedSources,
+	"github":        newGitHubSources,
+	"stackexchange": newStackExchangeSources,
+	"mastodon":      newMastodonSources,
+}
+
+// appendComments adds a comment tree to the content of item, indented by
+// depth, until maxCommentContext is reached.
+func appendComments(item *Item, comments []*Item) {
+	if len(comments) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(item.Content)
+	b.WriteString("\n\n---\nComments:\n")
+	for _, c := range comments {
+		line := fmt.Sprintf("%s- %s: %s\n", strings.Repeat("  ", c.Depth), c.Author, strings.Join(strings.Fields(c.Content), " "))
+		if b.Len()+len(line) > maxCommentContext {
+			break
+		}
+		b.WriteString(line)
+	}
+	item.Content = b.String()
+}
+
+func newSources(cfg *config.Config) ([]Source, error) {
+	var sources []Source
+	for _, name := range cfg.Crawler.Sources {
+		build, ok := sourceBuilders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q in crawler.sources", name)
+		}
+		built, err := build(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("create %s source: %w", name, err)
+		}
+		sources = append(sources, built...)
+	}
+	return sources, nil
+}
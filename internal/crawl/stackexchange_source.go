@@ -0,0 +1,99 @@
+package crawl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/stackexchange"
+)
+
+// stackExchangeSource crawls one stackexchange.sites listing. Questions are
+// analyzed together with their best answers, which usually name the tools
+// people recommend.
+type stackExchangeSource struct {
+	client *stackexchange.Client
+	config *config.Config
+	site   config.StackExchangeSite
+}
+
+func newStackExchangeSources(cfg *config.Config) ([]Source, error) {
+	client := stackexchange.NewClient(cfg.StackExchange.BaseURL, cfg.StackExchange.Key)
+
+	sources := make([]Source, 0, len(cfg.StackExchange.Sites))
+	for _, site := range cfg.StackExchange.Sites {
+		sources = append(sources, &stackExchangeSource{client: client, config: cfg, site: site})
+	}
+	return sources, nil
+}
+
+func (s *stackExchangeSource) Name() string {
+	return "stackexchange"
+}
+
+func (s *stackExchangeSource) Channel() string {
+	return s.site.Name
+}
+
+func (s *stackExchangeSource) ListItems(ctx context.Context) ([]*Item, error) {
+	questions, err := s.client.ListQuestions(ctx, s.site.Site, stackexchange.QuestionOptions{
+		Listing: s.site.Listing,
+		Tagged:  s.site.Tagged,
+		Query:   s.site.Query,
+		Limit:   s.site.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(questions))
+	for _, q := range questions {
+		items = append(items, &Item{
+			SourceItem: database.SourceItem{
+				Channel:         s.site.Name,
+				SourceItemID:    s.site.Site + "/" + q.ID,
+				Title:           q.Title,
+				Content:         q.Body,
+				Author:          q.Author,
+				URL:             q.URL,
+				Score:           q.Score,
+				SourceCreatedAt: q.CreatedAt,
+				NumComments:     q.AnswerCount,
+				IsSelf:          true,
+			},
+			Focus:        analysis.Focus(s.site.Focus),
+			WithComments: q.AnswerCount > 0,
+		})
+	}
+	return items, nil
+}
+
+// FetchComments returns the answers to a question, highest voted first.
+func (s *stackExchangeSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	questionID := strings.TrimPrefix(item.SourceItemID, s.site.Site+"/")
+	answers, err := s.client.ListAnswers(ctx, s.site.Site, questionID, s.config.StackExchange.MaxAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*Item, 0, len(answers))
+	for _, a := range answers {
+		comments = append(comments, &Item{
+			SourceItem: database.SourceItem{
+				Channel:         item.Channel,
+				SourceItemID:    s.site.Site + "/a/" + a.ID,
+				Title:           item.Title,
+				Content:         a.Body,
+				Author:          a.Author,
+				URL:             a.URL,
+				Score:           a.Score,
+				SourceCreatedAt: a.CreatedAt,
+				ParentItemID:    item.SourceItemID,
+			},
+			Focus: item.Focus,
+		})
+	}
+	return comments, nil
+}
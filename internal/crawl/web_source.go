@@ -0,0 +1,119 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/webpage"
+)
+
+// Pages with less readable text than this are navigation or login walls.
+const minWebPageLength = 200
+
+// PageStore tells which pages were already imported, *database.DB is one.
+type PageStore interface {
+	SourceItemExists(source string, sourceItemID string) (bool, error)
+}
+
+// webSource ingests single web pages such as blog posts and forum threads.
+// Pages are deduplicated by their canonical URL without tracking
+// parameters.
+type webSource struct {
+	client *webpage.Client
+	urls   []string
+	store  PageStore // nil fetches every page
+}
+
+// NewWebSource creates a source fetching the given page URLs. URLs already
+// recorded in store are not downloaded again.
+func NewWebSource(urls []string, store PageStore) (Source, error) {
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("invalid page URL %q", u)
+		}
+	}
+	return &webSource{client: webpage.NewClient(), urls: urls, store: store}, nil
+}
+
+func (s *webSource) Name() string {
+	return "web"
+}
+
+func (s *webSource) Channel() string {
+	if len(s.urls) == 1 {
+		return s.urls[0]
+	}
+	return fmt.Sprintf("%d pages", len(s.urls))
+}
+
+// ListItems fetches every page not imported yet. A page that fails is
+// logged and left out so one dead link does not stop a list.
+func (s *webSource) ListItems(ctx context.Context) ([]*Item, error) {
+	items := make([]*Item, 0, len(s.urls))
+	for _, u := range s.urls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// A page whose canonical URL differs is only known after the
+		// download, the crawler skips it then
+		if s.store != nil {
+			exists, err := s.store.SourceItemExists(s.Name(), webpage.NormalizeURL(u))
+			if err != nil {
+				return nil, fmt.Errorf("failed to check page %s: %w", u, err)
+			}
+			if exists {
+				continue
+			}
+		}
+
+		page, err := s.client.Fetch(ctx, u)
+		if err != nil {
+			log.Printf("Failed to fetch page %s: %v", u, err)
+			continue
+		}
+
+		items = append(items, pageItem(page))
+	}
+	return items, nil
+}
+
+// FetchComments is not supported, replies on the page are part of its text
+// when the extractor keeps them.
+func (s *webSource) FetchComments(ctx context.Context, item *Item) ([]*Item, error) {
+	return nil, fmt.Errorf("web pages have no separate comments")
+}
+
+func pageItem(page *webpage.Page) *Item {
+	channel := page.SiteName
+	if u, err := url.Parse(page.URL); err == nil && channel == "" {
+		channel = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	title := page.Title
+	if title == "" {
+		title = page.URL
+	}
+
+	item := &Item{
+		SourceItem: database.SourceItem{
+			Channel:         channel,
+			SourceItemID:    webpage.NormalizeURL(page.URL),
+			Title:           title,
+			Content:         page.Text,
+			Author:          page.Author,
+			URL:             page.URL,
+			SourceCreatedAt: page.Published,
+			IsSelf:          true,
+		},
+	}
+	if len(page.Text) < minWebPageLength {
+		item.Skip = "without readable content"
+	}
+	return item
+}
@@ -0,0 +1,82 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// stubPageStore knows the pages imported before.
+type stubPageStore map[string]bool
+
+func (s stubPageStore) SourceItemExists(source string, sourceItemID string) (bool, error) {
+	return s[source+" "+sourceItemID], nil
+}
+
+func newStubWebServer(t *testing.T, requested *[]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		*requested = append(*requested, r.URL.Path)
+		http.ServeFile(w, r, "../webpage/testdata/article.html")
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		*requested = append(*requested, r.URL.Path)
+		http.ServeFile(w, r, "../webpage/testdata/short.html")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebListItems(t *testing.T) {
+	var requested []string
+	srv := newStubWebServer(t, &requested)
+
+	src, err := NewWebSource([]string{srv.URL + "/article", srv.URL + "/missing", srv.URL + "/login"}, nil)
+	if err != nil {
+		t.Fatalf("NewWebSource: %v", err)
+	}
+	items, err := src.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	// The dead link is left out
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	article, login := items[0], items[1]
+	if article.SourceItemID != srv.URL+"/posts/invoicing" || article.Channel != "Maker Notes" || article.Skip != "" {
+		t.Fatalf("unexpected article item %+v", article)
+	}
+	if login.Skip == "" {
+		t.Fatalf("page with %d characters of text not skipped", len(login.Content))
+	}
+}
+
+func TestWebListItemsSkipsKnownPages(t *testing.T) {
+	var requested []string
+	srv := newStubWebServer(t, &requested)
+	store := stubPageStore{"web " + srv.URL + "/login": true}
+
+	src, err := NewWebSource([]string{srv.URL + "/article", srv.URL + "/login#top"}, store)
+	if err != nil {
+		t.Fatalf("NewWebSource: %v", err)
+	}
+	items, err := src.ListItems(context.Background())
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	if len(items) != 1 || !slices.Equal(requested, []string{"/article"}) {
+		t.Fatalf("got %d items from %v, want the article only", len(items), requested)
+	}
+}
+
+func TestNewWebSourceRejectsOtherSchemes(t *testing.T) {
+	if _, err := NewWebSource([]string{"ftp://example.com/file"}, nil); err == nil {
+		t.Fatal("expected an error for a URL that is not http")
+	}
+}
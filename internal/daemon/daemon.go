@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/letieu/idea-extractor/config"
+	"github.com/letieu/idea-extractor/internal/crawl"
+	"github.com/letieu/idea-extractor/internal/database"
+	"github.com/letieu/idea-extractor/internal/group"
+	"github.com/robfig/cron/v3"
+)
+
+// Daemon runs the configured crawl schedules one at a time and groups the
+// new items after every crawl. Jobs take the same database locks as
+// cmd/crawler and cmd/grouper, so two daemons, or a daemon and a crawl
+// started by hand, never overlap.
+type Daemon struct {
+	config  *config.Config
+	db      *database.DB
+	crawler *crawl.Crawler
+	grouper *group.Groupper
+
+	startedAt time.Time
+
+	// Guards the job states and running, read by the health endpoint
+	mu       sync.Mutex
+	jobs     []*job
+	running  string
+	stopping bool
+}
+
+// job is a schedule with the state of its last run.
+type job struct {
+	schedule  config.Schedule
+	cron      cron.Schedule
+	next      time.Time
+	lastStart time.Time
+	lastEnd   time.Time
+	lastError string
+}
+
+func New(cfg *config.Config, db *database.DB, crawler *crawl.Crawler, grouper *group.Groupper) (*Daemon, error) {
+	if len(cfg.Daemon.Schedules) == 0 {
+		return nil, fmt.Errorf("daemon.schedules is empty, nothing to run")
+	}
+
+	d := &Daemon{
+		config:    cfg,
+		db:        db,
+		crawler:   crawler,
+		grouper:   grouper,
+		startedAt: time.Now(),
+	}
+
+	for _, sched := range cfg.Daemon.Schedules {
+		parsed, err := cron.ParseStandard(sched.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", sched.Name, err)
+		}
+		d.jobs = append(d.jobs, &job{
+			schedule: sched,
+			cron:     parsed,
+			next:     parsed.Next(d.startedAt),
+		})
+	}
+	return d, nil
+}
+
+// Run waits for the schedules and runs their jobs until ctx is cancelled.
+// A cancelled crawl still stores the items already analyzed, Run returns
+// once it did. Runs missed while another job was running are skipped.
+func (d *Daemon) Run(ctx context.Context) {
+	for {
+		d.mu.Lock()
+		next := d.jobs[0].next
+		for _, j := range d.jobs[1:] {
+			if j.next.Before(next) {
+				next = j.next
+			}
+		}
+		d.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.setStopping()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for _, j := range d.jobs {
+			if j.next.After(now) {
+				continue
+			}
+			d.runJob(ctx, j)
+			if ctx.Err() != nil {
+				d.setStopping()
+				return
+			}
+		}
+	}
+}
+
+func (d *Daemon) setStopping() {
+	d.mu.Lock()
+	d.stopping = true
+	d.mu.Unlock()
+}
+
+func (d *Daemon) runJob(ctx context.Context, j *job) {
+	name := j.schedule.Name
+	log.Printf("Running schedule %s", name)
+
+	d.mu.Lock()
+	d.running = name
+	j.lastStart = time.Now()
+	d.mu.Unlock()
+
+	err := d.db.WithLock(ctx, database.LockCrawl, d.config.Daemon.LockTTL, func(ctx context.Context) error {
+		return d.crawler.CrawlChannels(ctx, j.schedule.Source, j.schedule.Channels)
+	})
+	switch {
+	case errors.Is(err, database.ErrLocked):
+		log.Printf("Skipping schedule %s, another crawl is running", name)
+	case ctx.Err() != nil:
+		log.Printf("Schedule %s interrupted, not grouping", name)
+	default:
+		if err != nil {
+			log.Printf("Crawl of schedule %s failed, grouping what it stored: %v", name, err)
+		}
+		groupErr := d.db.WithLock(ctx, database.LockGroup, d.config.Daemon.LockTTL, d.grouper.ProcessSourceItems)
+		if errors.Is(groupErr, database.ErrLocked) {
+			log.Printf("Skipping grouping, another grouping is running")
+		} else if groupErr != nil {
+			log.Printf("Failed to group source items: %v", groupErr)
+			err = errors.Join(err, groupErr)
+		}
+	}
+
+	d.mu.Lock()
+	d.running = ""
+	j.lastEnd = time.Now()
+	j.lastError = ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+	j.next = j.cron.Next(j.lastEnd)
+	d.mu.Unlock()
+
+	log.Printf("Schedule %s done, next run at %s", name, j.next.Format(time.DateTime))
+}
+
+type health struct {
+	Status    string           `json:"status"` // ok, or stopping once a shutdown started
+	StartedAt time.Time        `json:"started_at"`
+	Running   string           `json:"running,omitempty"`
+	Schedules []scheduleHealth `json:"schedules"`
+}
+
+type scheduleHealth struct {
+	Name      string    `json:"name"`
+	Cron      string    `json:"cron"`
+	NextRun   time.Time `json:"next_run"`
+	LastStart time.Time `json:"last_start,omitzero"`
+	LastEnd   time.Time `json:"last_end,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// ServeHTTP reports the state of the schedules as JSON. It answers 503 once
+// the daemon is shutting down.
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	h := health{
+		Status:    "ok",
+		StartedAt: d.startedAt,
+		Running:   d.running,
+	}
+	for _, j := range d.jobs {
+		h.Schedules = append(h.Schedules, scheduleHealth{
+			Name:      j.schedule.Name,
+			Cron:      j.schedule.Cron,
+			NextRun:   j.next,
+			LastStart: j.lastStart,
+			LastEnd:   j.lastEnd,
+			LastError: j.lastError,
+		})
+	}
+	stopping := d.stopping
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if stopping {
+		h.Status = "stopping"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
@@ -1,11 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/letieu/idea-extractor/config"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
@@ -31,6 +35,10 @@ func NewDB(cfg *config.Config) (*DB, error) {
 	}
 
 	db := &DB{conn: conn}
+	if err := db.migrate(); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
 	return db, nil
 }
@@ -188,21 +196,59 @@ func (db *DB) CreateProduct(product *Product) (int, error) {
 	return int(insertedID), nil
 }
 
+// FindProductByName returns the ID of the oldest product with the given
+// name, compared case-insensitively, or 0 when there is none.
+func (db *DB) FindProductByName(name string) (int, error) {
+	var id int
+	err := db.conn.QueryRow(`SELECT id FROM products WHERE name = ? COLLATE NOCASE ORDER BY id LIMIT 1`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find product: %w", err)
+	}
+	return id, nil
+}
+
 func (db *DB) CreateSourceItem(item *SourceItem, analysisResult string) error {
+	status := item.Status
+	if status == "" {
+		status = StatusAnalyzed
+	}
+
 	query := `
-        INSERT INTO source_items (source, source_item_id, title, content, author, url, score, analysis_result, source_created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+        INSERT INTO source_items (source, channel, source_item_id, title, content, author, url, score, analysis_result, source_created_at, parent_item_id,
+            num_comments, upvote_ratio, flair, nsfw, is_self, stickied, edited_at, external_url, subreddit_subscribers,
+            status, analysis_mode, analysis_focus, attempts, last_error, next_retry_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := db.conn.Exec(query,
 		item.Source,
+		nullString(item.Channel),
 		item.SourceItemID,
 		item.Title,
 		item.Content,
 		item.Author,
 		item.URL,
 		item.Score,
-		analysisResult,
+		nullString(analysisResult),
 		item.SourceCreatedAt.Format("2006-01-02 15:04:05"),
+		nullString(item.ParentItemID),
+		item.NumComments,
+		item.UpvoteRatio,
+		nullString(item.Flair),
+		item.NSFW,
+		item.IsSelf,
+		item.Stickied,
+		nullTime(item.EditedAt.UTC()),
+		nullString(item.ExternalURL),
+		item.SubredditSubscribers,
+		status,
+		nullString(item.AnalysisMode),
+		nullString(item.AnalysisFocus),
+		item.Attempts,
+		nullString(item.LastError),
+		nullTime(item.NextRetryAt.UTC()),
 	)
 	if err != nil {
 		return err
@@ -211,6 +257,140 @@ func (db *DB) CreateSourceItem(item *SourceItem, analysisResult string) error {
 	return nil
 }
 
+// UpdateSourceItemAnalysis stores the outcome of analyzing an existing item
+// again: its status, result and retry state.
+func (db *DB) UpdateSourceItemAnalysis(item *SourceItem) error {
+	query := `UPDATE source_items
+	SET status = ?, analysis_result = ?, attempts = ?, last_error = ?, next_retry_at = ?
+	WHERE id = ?`
+
+	_, err := db.conn.Exec(query,
+		item.Status,
+		nullString(item.AnalysisResult),
+		item.Attempts,
+		nullString(item.LastError),
+		nullTime(item.NextRetryAt.UTC()),
+		item.ID,
+	)
+	return err
+}
+
+// GetRetryableSourceItems returns failed items whose next retry time has
+// passed, the longest waiting first.
+func (db *DB) GetRetryableSourceItems(limit int) ([]*SourceItem, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, source, channel, source_item_id, title, content, analysis_mode, analysis_focus, attempts, last_error
+		FROM source_items
+		WHERE status = ? AND next_retry_at <= ?
+		ORDER BY next_retry_at
+		LIMIT ?
+	`, StatusFailed, time.Now().UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*SourceItem
+	for rows.Next() {
+		var item SourceItem
+		var channel, mode, focus, lastError sql.NullString
+		if err := rows.Scan(
+			&item.ID,
+			&item.Source,
+			&channel,
+			&item.SourceItemID,
+			&item.Title,
+			&item.Content,
+			&mode,
+			&focus,
+			&item.Attempts,
+			&lastError,
+		); err != nil {
+			return nil, err
+		}
+		item.Status = StatusFailed
+		item.Channel = channel.String
+		item.AnalysisMode = mode.String
+		item.AnalysisFocus = focus.String
+		item.LastError = lastError.String
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// ListSourceItemsByStatus returns the newest items with one of statuses,
+// optionally only from source, for analysts to review.
+func (db *DB) ListSourceItemsByStatus(statuses []string, source string, limit int) ([]*SourceItem, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT id, source, channel, source_item_id, title, url, status, analysis_result, last_error, attempts, created_at
+		FROM source_items
+		WHERE status IN (?` + strings.Repeat(", ?", len(statuses)-1) + `)`
+	args := make([]any, 0, len(statuses)+2)
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+	if source != "" {
+		query += ` AND source = ?`
+		args = append(args, source)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*SourceItem
+	for rows.Next() {
+		var item SourceItem
+		var channel, url, analysisResult, lastError sql.NullString
+		if err := rows.Scan(
+			&item.ID,
+			&item.Source,
+			&channel,
+			&item.SourceItemID,
+			&item.Title,
+			&url,
+			&item.Status,
+			&analysisResult,
+			&lastError,
+			&item.Attempts,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		item.Channel = channel.String
+		item.URL = url.String
+		item.AnalysisResult = analysisResult.String
+		item.LastError = lastError.String
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// RequeueSourceItem marks a rejected or abandoned item as failed with no
+// attempts, so the retry command analyzes it again on its next run.
+func (db *DB) RequeueSourceItem(id int) error {
+	result, err := db.conn.Exec(`UPDATE source_items
+	SET status = ?, attempts = 0, next_retry_at = ?
+	WHERE id = ? AND status IN (?, ?, ?)`,
+		StatusFailed, time.Now().UTC().Format("2006-01-02 15:04:05"),
+		id, StatusMeta, StatusEmpty, StatusAbandoned,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("source item %d not found or not rejected", id)
+	}
+	return nil
+}
+
 func (db *DB) SourceItemExists(source string, sourceItemID string) (bool, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM source_items WHERE source = ? AND source_item_id = ?`
@@ -220,10 +400,10 @@ func (db *DB) SourceItemExists(source string, sourceItemID string) (bool, error)
 
 func (db *DB) GetUngroupedSourceItems() ([]*SourceItem, error) {
 	rows, err := db.conn.Query(`
-		SELECT rowid, source, source_item_id, title, content, author, url, score, analysis_result, created_at, source_created_at, problem_id, idea_id, product_id
+		SELECT rowid, source, channel, source_item_id, title, content, author, url, score, analysis_result, created_at, source_created_at, parent_item_id, analysis_mode, problem_id, idea_id, product_id
 		FROM source_items
-		WHERE problem_id IS NULL AND idea_id IS NULL AND product_id IS NULL
-	`)
+		WHERE problem_id IS NULL AND idea_id IS NULL AND product_id IS NULL AND status = ?
+	`, StatusAnalyzed)
 	if err != nil {
 		return nil, err
 	}
@@ -232,10 +412,11 @@ func (db *DB) GetUngroupedSourceItems() ([]*SourceItem, error) {
 	var items []*SourceItem
 	for rows.Next() {
 		var item SourceItem
-		var problemID, ideaID, productID sql.NullString
+		var channel, parentItemID, mode, problemID, ideaID, productID sql.NullString
 		if err := rows.Scan(
 			&item.ID,
 			&item.Source,
+			&channel,
 			&item.SourceItemID,
 			&item.Title,
 			&item.Content,
@@ -245,12 +426,21 @@ func (db *DB) GetUngroupedSourceItems() ([]*SourceItem, error) {
 			&item.AnalysisResult,
 			&item.CreatedAt,
 			&item.SourceCreatedAt,
+			&parentItemID,
+			&mode,
 			&problemID,
 			&ideaID,
 			&productID,
 		); err != nil {
 			return nil, err
 		}
+		if channel.Valid {
+			item.Channel = channel.String
+		}
+		if parentItemID.Valid {
+			item.ParentItemID = parentItemID.String
+		}
+		item.AnalysisMode = mode.String
 		if problemID.Valid {
 			item.ProblemID = problemID.String
 		}
@@ -370,6 +560,266 @@ func (db *DB) UpdateSourceItemProductID(ids []int, productID int) error {
 	return err
 }
 
+// GetImportedReviewIDs returns the IDs of the reviews of product already
+// stored in a batch.
+func (db *DB) GetImportedReviewIDs(product string) (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT review_id FROM imported_reviews WHERE product = ?`, product)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// CreateImportedReviews records the reviews of product stored in the batch
+// sourceItemID.
+func (db *DB) CreateImportedReviews(product, sourceItemID string, reviewIDs []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range reviewIDs {
+		_, err := tx.Exec(`INSERT OR IGNORE INTO imported_reviews (product, review_id, source_item_id) VALUES (?, ?, ?)`,
+			product, id, sourceItemID)
+		if err != nil {
+			return fmt.Errorf("failed to insert imported review: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateCrawlRun stores a run when its crawl starts and returns its ID.
+func (db *DB) CreateCrawlRun(run *CrawlRun) (int, error) {
+	result, err := db.conn.Exec(`INSERT INTO crawl_runs (source, channel, started_at) VALUES (?, ?, ?)`,
+		run.Source,
+		nullString(run.Channel),
+		run.StartedAt.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert crawl run: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// UpdateCrawlRun stores the end time and counts of a run.
+func (db *DB) UpdateCrawlRun(run *CrawlRun) error {
+	query := `UPDATE crawl_runs
+	SET finished_at = ?, fetched = ?, skipped_existing = ?, skipped_filtered = ?, analyzed = ?, meta = ?, empty = ?, failed = ?,
+		llm_calls = ?, prompt_tokens = ?, completion_tokens = ?, llm_latency_ms = ?, error = ?
+	WHERE id = ?`
+
+	_, err := db.conn.Exec(query,
+		nullTime(run.FinishedAt.UTC()),
+		run.Fetched,
+		run.SkippedExisting,
+		run.SkippedFiltered,
+		run.Analyzed,
+		run.Meta,
+		run.Empty,
+		run.Failed,
+		run.LLMCalls,
+		run.PromptTokens,
+		run.CompletionTokens,
+		run.LLMLatency.Milliseconds(),
+		nullString(run.Error),
+		run.ID,
+	)
+	return err
+}
+
+// ListCrawlRuns returns the latest runs, optionally only those of source
+// and channel.
+func (db *DB) ListCrawlRuns(source, channel string, limit int) ([]*CrawlRun, error) {
+	query := `SELECT id, source, channel, started_at, finished_at, fetched, skipped_existing, skipped_filtered,
+		analyzed, meta, empty, failed, llm_calls, prompt_tokens, completion_tokens, llm_latency_ms, error
+		FROM crawl_runs WHERE 1 = 1`
+	var args []any
+	if source != "" {
+		query += ` AND source = ?`
+		args = append(args, source)
+	}
+	if channel != "" {
+		query += ` AND channel = ? COLLATE NOCASE`
+		args = append(args, channel)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*CrawlRun
+	for rows.Next() {
+		var run CrawlRun
+		var channel, runErr sql.NullString
+		var finishedAt sql.NullTime
+		var latencyMs int64
+		if err := rows.Scan(
+			&run.ID,
+			&run.Source,
+			&channel,
+			&run.StartedAt,
+			&finishedAt,
+			&run.Fetched,
+			&run.SkippedExisting,
+			&run.SkippedFiltered,
+			&run.Analyzed,
+			&run.Meta,
+			&run.Empty,
+			&run.Failed,
+			&run.LLMCalls,
+			&run.PromptTokens,
+			&run.CompletionTokens,
+			&latencyMs,
+			&runErr,
+		); err != nil {
+			return nil, err
+		}
+		run.Channel = channel.String
+		run.FinishedAt = finishedAt.Time
+		run.LLMLatency = time.Duration(latencyMs) * time.Millisecond
+		run.Error = runErr.String
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// GetCrawlRunTrends sums the runs started since since per source channel
+// and week, oldest week first.
+func (db *DB) GetCrawlRunTrends(since time.Time) ([]*CrawlRunTrend, error) {
+	rows, err := db.conn.Query(`
+		SELECT source, COALESCE(channel, ''), strftime('%Y-%W', started_at) AS week, COUNT(*),
+			SUM(fetched), SUM(analyzed), SUM(meta + empty), SUM(failed), SUM(prompt_tokens + completion_tokens)
+		FROM crawl_runs
+		WHERE started_at >= ?
+		GROUP BY source, channel, week
+		ORDER BY source, channel, week
+	`, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trends []*CrawlRunTrend
+	for rows.Next() {
+		var t CrawlRunTrend
+		if err := rows.Scan(&t.Source, &t.Channel, &t.Week, &t.Runs, &t.Fetched, &t.Analyzed, &t.Rejected, &t.Failed, &t.Tokens); err != nil {
+			return nil, err
+		}
+		trends = append(trends, &t)
+	}
+	return trends, rows.Err()
+}
+
+// AcquireLock takes the lock name for owner until ttl from now. It returns
+// false when another owner holds it and it has not expired. The holder
+// calls it again before the ttl runs out to keep the lock.
+func (db *DB) AcquireLock(name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result, err := db.conn.Exec(`INSERT INTO locks (name, owner, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+	WHERE locks.owner = excluded.owner OR locks.expires_at < ?`,
+		name, owner, now.Add(ttl).Format("2006-01-02 15:04:05"),
+		now.Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReleaseLock frees the lock name if owner holds it.
+func (db *DB) ReleaseLock(name, owner string) error {
+	_, err := db.conn.Exec(`DELETE FROM locks WHERE name = ? AND owner = ?`, name, owner)
+	return err
+}
+
+// Names of the locks taken by the commands that write. Crawls and grouping
+// lock separately so grouping can run while a crawl is analyzing.
+const (
+	LockCrawl = "crawl"
+	LockGroup = "group"
+)
+
+// ErrLocked is returned by WithLock when another process holds the lock.
+var ErrLocked = errors.New("lock is held by another process")
+
+// WithLock runs fn while holding the lock name, refreshing it every third
+// of ttl. If a refresh fails the context of fn is cancelled, as another
+// process may take the lock once it expires.
+func (db *DB) WithLock(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d", host, os.Getpid())
+
+	acquired, err := db.AcquireLock(name, owner, ttl)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	defer func() {
+		if err := db.ReleaseLock(name, owner); err != nil {
+			log.Printf("Failed to release lock %s: %v", name, err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ok, err := db.AcquireLock(name, owner, ttl)
+				if err == nil && !ok {
+					err = errors.New("taken by another process")
+				}
+				if err != nil {
+					log.Printf("Failed to refresh lock %s, stopping: %v", name, err)
+					cancel(fmt.Errorf("lost lock %s: %w", name, err))
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(ctx)
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
@@ -410,6 +860,17 @@ func (db *DB) LinkIdeaProduct(ideaId, productId int) error {
 	return err
 }
 
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.Format("2006-01-02 15:04:05"), Valid: true}
+}
+
 func vector32String(arr []float32) string {
 	parts := make([]string, len(arr))
 	for i, v := range arr {
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"fmt"
+)
+
+// init.sql drops and recreates every table. Databases created by an older
+// init.sql are upgraded in place by migrate, which adds what was introduced
+// since and can run any number of times.
+
+// addedColumn is a column added to a table after it was first released.
+type addedColumn struct {
+	table, name, definition string
+}
+
+var addedColumns = []addedColumn{
+	{"source_items", "parent_item_id", "TEXT"},
+	{"source_items", "channel", "TEXT"},
+
+	{"source_items", "num_comments", "INTEGER DEFAULT 0"},
+	{"source_items", "upvote_ratio", "REAL"},
+	{"source_items", "flair", "TEXT"},
+	{"source_items", "nsfw", "BOOLEAN DEFAULT 0"},
+	{"source_items", "is_self", "BOOLEAN DEFAULT 1"},
+	{"source_items", "stickied", "BOOLEAN DEFAULT 0"},
+	{"source_items", "edited_at", "DATETIME"},
+	{"source_items", "external_url", "TEXT"},
+	{"source_items", "subreddit_subscribers", "INTEGER"},
+
+	{"source_items", "status", "TEXT NOT NULL DEFAULT 'analyzed'"},
+	{"source_items", "analysis_mode", "TEXT"},
+	{"source_items", "analysis_focus", "TEXT"},
+	{"source_items", "attempts", "INTEGER DEFAULT 0"},
+	{"source_items", "last_error", "TEXT"},
+	{"source_items", "next_retry_at", "DATETIME"},
+}
+
+// addedStatements create the tables and indexes added after the first
+// release, they must be idempotent.
+var addedStatements = []string{
+	`CREATE INDEX IF NOT EXISTS idx_source_items_retry ON source_items (status, next_retry_at)`,
+	`CREATE TABLE IF NOT EXISTS crawl_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source TEXT NOT NULL,
+		channel TEXT,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME,
+		fetched INTEGER DEFAULT 0,
+		skipped_existing INTEGER DEFAULT 0,
+		skipped_filtered INTEGER DEFAULT 0,
+		analyzed INTEGER DEFAULT 0,
+		meta INTEGER DEFAULT 0,
+		empty INTEGER DEFAULT 0,
+		failed INTEGER DEFAULT 0,
+		llm_calls INTEGER DEFAULT 0,
+		prompt_tokens INTEGER DEFAULT 0,
+		completion_tokens INTEGER DEFAULT 0,
+		llm_latency_ms INTEGER DEFAULT 0,
+		error TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_crawl_runs_channel ON crawl_runs (source, channel, started_at)`,
+	`CREATE TABLE IF NOT EXISTS locks (
+		name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS imported_reviews (
+		product TEXT NOT NULL COLLATE NOCASE,
+		review_id TEXT NOT NULL,
+		source_item_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (product, review_id)
+	)`,
+}
+
+// migrate brings the schema of an existing database up to date. A database
+// without source_items was never initialized and is left to init.sql.
+func (db *DB) migrate() error {
+	columns := map[string]map[string]bool{}
+	for _, c := range addedColumns {
+		if columns[c.table] == nil {
+			existing, err := db.tableColumns(c.table)
+			if err != nil {
+				return err
+			}
+			columns[c.table] = existing
+		}
+		if len(columns[c.table]) == 0 {
+			return nil
+		}
+		if columns[c.table][c.name] {
+			continue
+		}
+		if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", c.table, c.name, err)
+		}
+		columns[c.table][c.name] = true
+	}
+
+	for _, stmt := range addedStatements {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the column names of table, none when it does not
+// exist.
+func (db *DB) tableColumns(table string) (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
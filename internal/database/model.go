@@ -41,10 +41,21 @@ type Product struct {
 	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
 }
 
+// Source item statuses. Only analyzed items are grouped, rejected meta and
+// empty items are kept so they are not analyzed again.
+const (
+	StatusAnalyzed  = "analyzed"
+	StatusMeta      = "meta"      // The analyzer found a meta post
+	StatusEmpty     = "empty"     // The analyzer found nothing worth keeping
+	StatusFailed    = "failed"    // Analysis failed, retried at NextRetryAt
+	StatusAbandoned = "abandoned" // Analysis failed too many times
+)
+
 // SourceItem represents a raw item from a source.
 type SourceItem struct {
 	ID              int       `json:"id" bson:"_id"` // Auto-incrementing integer for sqlite-vec
 	Source          string    `json:"source" bson:"source"`
+	Channel         string    `json:"channel" bson:"channel"` // Subreddit, feed or other place within the source
 	SourceItemID    string    `json:"source_item_id" bson:"source_item_id"`
 	Title           string    `json:"title" bson:"title"`
 	Content         string    `json:"content" bson:"content"`
@@ -54,6 +65,26 @@ type SourceItem struct {
 	AnalysisResult  string    `json:"analysis_result" bson:"analysis_result"` // JSON of the analysis
 	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
 	SourceCreatedAt time.Time `json:"source_created_at" bson:"source_created_at"`
+	ParentItemID    string    `json:"parent_item_id" bson:"parent_item_id"` // SourceItemID of the thread a comment was taken from
+
+	// Source metadata, not every source fills all of it
+	NumComments          int       `json:"num_comments" bson:"num_comments"`
+	UpvoteRatio          float64   `json:"upvote_ratio" bson:"upvote_ratio"`
+	Flair                string    `json:"flair" bson:"flair"`
+	NSFW                 bool      `json:"nsfw" bson:"nsfw"`
+	IsSelf               bool      `json:"is_self" bson:"is_self"`
+	Stickied             bool      `json:"stickied" bson:"stickied"`
+	EditedAt             time.Time `json:"edited_at" bson:"edited_at"`
+	ExternalURL          string    `json:"external_url" bson:"external_url"`
+	SubredditSubscribers int       `json:"subreddit_subscribers" bson:"subreddit_subscribers"`
+
+	// Analysis state, failed items keep what is needed to analyze them again
+	Status        string    `json:"status" bson:"status"`
+	AnalysisMode  string    `json:"analysis_mode" bson:"analysis_mode"` // "", products or review
+	AnalysisFocus string    `json:"analysis_focus" bson:"analysis_focus"`
+	Attempts      int       `json:"attempts" bson:"attempts"`
+	LastError     string    `json:"last_error" bson:"last_error"`
+	NextRetryAt   time.Time `json:"next_retry_at" bson:"next_retry_at"`
 
 	// Link to the grouped entities
 	ProblemID string `json:"problem_id" bson:"problem_id"`
@@ -61,6 +92,44 @@ type SourceItem struct {
 	ProductID string `json:"product_id" bson:"product_id"`
 }
 
+// CrawlRun is what one crawl of a source channel found and what analyzing
+// it cost. Runs without FinishedAt are in progress or were interrupted.
+type CrawlRun struct {
+	ID         int       `json:"id" bson:"_id"`
+	Source     string    `json:"source" bson:"source"`
+	Channel    string    `json:"channel" bson:"channel"`
+	StartedAt  time.Time `json:"started_at" bson:"started_at"`
+	FinishedAt time.Time `json:"finished_at" bson:"finished_at"`
+
+	Fetched         int `json:"fetched" bson:"fetched"`
+	SkippedExisting int `json:"skipped_existing" bson:"skipped_existing"`
+	SkippedFiltered int `json:"skipped_filtered" bson:"skipped_filtered"` // Stickied, NSFW, flair and other rules
+	Analyzed        int `json:"analyzed" bson:"analyzed"`
+	Meta            int `json:"meta" bson:"meta"`
+	Empty           int `json:"empty" bson:"empty"`
+	Failed          int `json:"failed" bson:"failed"`
+
+	LLMCalls         int           `json:"llm_calls" bson:"llm_calls"`
+	PromptTokens     int           `json:"prompt_tokens" bson:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens" bson:"completion_tokens"`
+	LLMLatency       time.Duration `json:"llm_latency" bson:"llm_latency"` // Total over all calls
+
+	Error string `json:"error" bson:"error"` // Why listing the source failed
+}
+
+// CrawlRunTrend sums the runs of a source channel over one week.
+type CrawlRunTrend struct {
+	Source   string `json:"source" bson:"source"`
+	Channel  string `json:"channel" bson:"channel"`
+	Week     string `json:"week" bson:"week"` // Year and week number, e.g. 2026-41
+	Runs     int    `json:"runs" bson:"runs"`
+	Fetched  int    `json:"fetched" bson:"fetched"`
+	Analyzed int    `json:"analyzed" bson:"analyzed"`
+	Rejected int    `json:"rejected" bson:"rejected"` // Meta and empty
+	Failed   int    `json:"failed" bson:"failed"`
+	Tokens   int    `json:"tokens" bson:"tokens"`
+}
+
 // ProblemIdeaLink links a Problem to an Idea that solves it.
 type ProblemIdea struct {
 	ProblemID string `json:"problem_id" bson:"problem_id"`
@@ -0,0 +1,199 @@
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+	"golang.org/x/net/html/charset"
+)
+
+// Client fetches RSS 2.0, RSS 1.0 and Atom feeds.
+type Client struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+// Entry is a feed item with its body converted to plain text.
+type Entry struct {
+	GUID      string // Falls back to the link when the feed has no IDs
+	Title     string
+	Link      string
+	Author    string
+	Content   string
+	Published time.Time
+}
+
+type document struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"` // RSS 1.0 puts items next to the channel
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomEntry struct {
+	ID    string   `xml:"id"`
+	Title atomText `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Author struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Content   atomText `xml:"content"`
+	Summary   atomText `xml:"summary"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+}
+
+// atomText is an Atom text construct. Text and escaped HTML are character
+// data, XHTML is inline markup wrapped in a div.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Data  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "idea-extractor/1.0 (+feed reader)",
+	}
+}
+
+// Fetch downloads and parses the feed at url.
+func (c *Client) Fetch(ctx context.Context, url string) ([]*Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("feed error %d: %s", resp.StatusCode, body)
+	}
+
+	return Parse(resp.Body)
+}
+
+// Parse reads an RSS or Atom document.
+func Parse(r io.Reader) ([]*Entry, error) {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charset.NewReaderLabel
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var entries []*Entry
+	for _, item := range append(doc.Channel.Items, doc.Items...) {
+		entries = append(entries, item.toEntry())
+	}
+	for _, entry := range doc.Entries {
+		entries = append(entries, entry.toEntry())
+	}
+	return entries, nil
+}
+
+func (item rssItem) toEntry() *Entry {
+	e := &Entry{
+		GUID:      strings.TrimSpace(item.GUID),
+		Title:     htmltext.ToText(item.Title),
+		Link:      strings.TrimSpace(item.Link),
+		Author:    firstNonEmpty(item.Creator, item.Author),
+		Content:   htmltext.ToText(firstNonEmpty(item.Encoded, item.Description)),
+		Published: parseDate(firstNonEmpty(item.PubDate, item.Date)),
+	}
+	if e.GUID == "" {
+		e.GUID = e.Link
+	}
+	return e
+}
+
+func (entry atomEntry) toEntry() *Entry {
+	e := &Entry{
+		GUID:      strings.TrimSpace(entry.ID),
+		Title:     entry.Title.text(),
+		Author:    strings.TrimSpace(entry.Author.Name),
+		Content:   firstNonEmpty(entry.Content.text(), entry.Summary.text()),
+		Published: parseDate(firstNonEmpty(entry.Published, entry.Updated)),
+	}
+	for _, link := range entry.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			e.Link = link.Href
+			break
+		}
+	}
+	if e.GUID == "" {
+		e.GUID = e.Link
+	}
+	return e
+}
+
+func (t atomText) text() string {
+	if t.Type == "xhtml" {
+		return htmltext.ToText(t.Inner)
+	}
+	return htmltext.ToText(t.Data)
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>Indie makers</title>
+  <item>
+    <guid isPermaLink="false">post-1</guid>
+    <title>Launching &amp; learning</title>
+    <link>https://example.com/posts/1</link>
+    <dc:creator>alice</dc:creator>
+    <description>Short summary</description>
+    <content:encoded><![CDATA[<p>I built a <b>tiny</b> invoicing tool.</p><p>Feedback welcome!</p>]]></content:encoded>
+    <pubDate>Mon, 05 Oct 2026 10:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>No guid here</title>
+    <link> https://example.com/posts/2 </link>
+    <author>bob@example.com (Bob)</author>
+    <description><![CDATA[Plain <i>description</i>]]></description>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Changelog</title>
+  <entry>
+    <id>urn:entry:text</id>
+    <title type="text">Plain title</title>
+    <link rel="alternate" href="https://example.com/text"/>
+    <author><name>carol</name></author>
+    <content type="text">Just words &amp; symbols</content>
+    <published>2026-10-05T10:00:00Z</published>
+  </entry>
+  <entry>
+    <id>urn:entry:html</id>
+    <title type="html">&lt;em&gt;Escaped&lt;/em&gt; title</title>
+    <link rel="edit" href="https://example.com/edit"/>
+    <link href="https://example.com/html"/>
+    <content type="html">&lt;p&gt;Escaped &lt;b&gt;HTML&lt;/b&gt; body&lt;/p&gt;</content>
+    <updated>2026-10-06T10:00:00Z</updated>
+  </entry>
+  <entry>
+    <id>urn:entry:xhtml</id>
+    <title>XHTML entry</title>
+    <link href="https://example.com/xhtml"/>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline <strong>XHTML</strong> body</p></div></content>
+  </entry>
+  <entry>
+    <title>Summary only, no id</title>
+    <link href="https://example.com/summary"/>
+    <summary type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">A <em>summary</em></div></summary>
+  </entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	entries, err := Parse(strings.NewReader(rssFeed))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	first := entries[0]
+	if first.GUID != "post-1" || first.Title != "Launching & learning" || first.Author != "alice" {
+		t.Fatalf("unexpected entry %+v", first)
+	}
+	if want := "I built a tiny invoicing tool.\n\nFeedback welcome!"; first.Content != want {
+		t.Fatalf("Content = %q, want the CDATA content:encoded as text %q", first.Content, want)
+	}
+	if !first.Published.Equal(time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Published = %s", first.Published)
+	}
+
+	second := entries[1]
+	if second.GUID != "https://example.com/posts/2" || second.Link != second.GUID {
+		t.Fatalf("GUID = %q, want the link when there is no guid", second.GUID)
+	}
+	if second.Content != "Plain description" || second.Author != "bob@example.com (Bob)" {
+		t.Fatalf("unexpected entry %+v", second)
+	}
+	if !second.Published.IsZero() {
+		t.Fatalf("Published = %s, want zero without a date", second.Published)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	entries, err := Parse(strings.NewReader(atomFeed))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []Entry{
+		{GUID: "urn:entry:text", Title: "Plain title", Link: "https://example.com/text", Author: "carol", Content: "Just words & symbols"},
+		{GUID: "urn:entry:html", Title: "Escaped title", Link: "https://example.com/html", Content: "Escaped HTML body"},
+		{GUID: "urn:entry:xhtml", Title: "XHTML entry", Link: "https://example.com/xhtml", Content: "Inline XHTML body"},
+		{GUID: "https://example.com/summary", Title: "Summary only, no id", Link: "https://example.com/summary", Content: "A summary"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		w := want[i]
+		if e.GUID != w.GUID || e.Title != w.Title || e.Link != w.Link || e.Author != w.Author || e.Content != w.Content {
+			t.Errorf("entry %d = %+v, want %+v", i, *e, w)
+		}
+	}
+
+	if !entries[0].Published.Equal(time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Published = %s", entries[0].Published)
+	}
+	if !entries[1].Published.Equal(time.Date(2026, 10, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Published = %s, want the updated date", entries[1].Published)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("not a feed <")); err == nil {
+		t.Fatal("expected an error for a document that is not XML")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rssFeed))
+	}))
+	defer srv.Close()
+
+	entries, err := NewClient().Fetch(context.Background(), srv.URL+"/feed.xml")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if _, err := NewClient().Fetch(context.Background(), srv.URL+"/missing.xml"); err == nil {
+		t.Fatal("expected an error for a 404")
+	}
+}
@@ -0,0 +1,248 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.github.com"
+
+// Client reads issues through the GitHub REST API. Repository discussions
+// are only exposed over GraphQL, which needs a token.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// Issue is an open issue, pull requests are left out.
+type Issue struct {
+	Number    int
+	Title     string
+	Body      string
+	Author    string
+	URL       string
+	Labels    []string
+	Reactions int
+	Comments  int
+	CreatedAt time.Time
+}
+
+// Discussion is a repository discussion.
+type Discussion struct {
+	Number    int
+	Title     string
+	Body      string
+	Author    string
+	URL       string
+	Category  string
+	Reactions int // Upvotes and reactions
+	Comments  int
+	CreatedAt time.Time
+}
+
+// Comment is a reply to an issue or discussion. Discussion replies to
+// comments have Depth 1.
+type Comment struct {
+	ID        string
+	Body      string
+	Author    string
+	URL       string
+	Reactions int
+	Depth     int
+	CreatedAt time.Time
+}
+
+type restUser struct {
+	Login string `json:"login"`
+}
+
+type restIssue struct {
+	Number  int      `json:"number"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	HTMLURL string   `json:"html_url"`
+	User    restUser `json:"user"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Reactions struct {
+		TotalCount int `json:"total_count"`
+	} `json:"reactions"`
+	Comments    int       `json:"comments"`
+	CreatedAt   time.Time `json:"created_at"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+type restComment struct {
+	ID        int64    `json:"id"`
+	Body      string   `json:"body"`
+	HTMLURL   string   `json:"html_url"`
+	User      restUser `json:"user"`
+	Reactions struct {
+		TotalCount int `json:"total_count"`
+	} `json:"reactions"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewClient creates a GitHub client. An empty baseURL uses api.github.com,
+// the token is optional for public issues.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+	}
+}
+
+// ListIssues returns the newest open issues of repo ("owner/name") that
+// carry label, or all open issues when label is empty.
+func (c *Client) ListIssues(ctx context.Context, repo, label string, limit int) ([]*Issue, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("sort", "created")
+	query.Set("direction", "desc")
+	query.Set("per_page", strconv.Itoa(perPage(limit)))
+	if label != "" {
+		query.Set("labels", label)
+	}
+
+	var resp []restIssue
+	if err := c.get(ctx, "/repos/"+repo+"/issues?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, r := range resp {
+		if r.PullRequest != nil {
+			continue
+		}
+		issue := &Issue{
+			Number:    r.Number,
+			Title:     r.Title,
+			Body:      r.Body,
+			Author:    r.User.Login,
+			URL:       r.HTMLURL,
+			Reactions: r.Reactions.TotalCount,
+			Comments:  r.Comments,
+			CreatedAt: r.CreatedAt,
+		}
+		for _, l := range r.Labels {
+			issue.Labels = append(issue.Labels, l.Name)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// ListIssueComments returns the first comments of an issue.
+func (c *Client) ListIssueComments(ctx context.Context, repo string, number, limit int) ([]*Comment, error) {
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=%d", repo, number, perPage(limit))
+
+	var resp []restComment
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*Comment, 0, len(resp))
+	for _, r := range resp {
+		comments = append(comments, &Comment{
+			ID:        strconv.FormatInt(r.ID, 10),
+			Body:      r.Body,
+			Author:    r.User.Login,
+			URL:       r.HTMLURL,
+			Reactions: r.Reactions.TotalCount,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return comments, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github error %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// graphql runs a GraphQL query and decodes its data field into out.
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]any, out any) error {
+	if c.token == "" {
+		return fmt.Errorf("github discussions need a token")
+	}
+
+	raw, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/graphql", bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("github graphql error: %s", resp.Errors[0].Message)
+	}
+
+	return json.Unmarshal(resp.Data, out)
+}
+
+// GitHub pages hold at most 100 items.
+func perPage(limit int) int {
+	if limit <= 0 || limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+func splitRepo(repo string) (owner, name string, err error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return "", "", fmt.Errorf("invalid github repo %q, expected owner/name", repo)
+	}
+	return owner, name, nil
+}
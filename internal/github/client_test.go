@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+const issuesJSON = `[
+  {"number": 12, "title": "Export to CSV", "body": "Please add CSV export.", "html_url": "https://github.com/acme/app/issues/12",
+   "user": {"login": "alice"}, "labels": [{"name": "enhancement"}, {"name": "ux"}], "reactions": {"total_count": 9},
+   "comments": 3, "created_at": "2026-10-01T10:00:00Z"},
+  {"number": 13, "title": "Add CSV exporter", "body": "Implements #12.", "html_url": "https://github.com/acme/app/pull/13",
+   "user": {"login": "bob"}, "labels": [], "reactions": {"total_count": 0},
+   "comments": 0, "created_at": "2026-10-02T10:00:00Z", "pull_request": {"url": "https://api.github.com/repos/acme/app/pulls/13"}}
+]`
+
+func TestListIssuesSkipsPullRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("state") != "open" || q.Get("labels") != "enhancement" || q.Get("per_page") != "5" {
+			t.Errorf("unexpected query %v", q)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(issuesJSON))
+	}))
+	defer srv.Close()
+
+	issues, err := NewClient(srv.URL, "tok").ListIssues(context.Background(), "acme/app", "enhancement", 5)
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want the pull request left out", len(issues))
+	}
+
+	issue := issues[0]
+	if issue.Number != 12 || issue.Author != "alice" || issue.Reactions != 9 || issue.Comments != 3 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if !slices.Equal(issue.Labels, []string{"enhancement", "ux"}) {
+		t.Fatalf("Labels = %v", issue.Labels)
+	}
+}
+
+func TestListIssuesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").ListIssues(context.Background(), "acme/gone", "", 5); err == nil {
+		t.Fatal("expected an error for a missing repository")
+	}
+}
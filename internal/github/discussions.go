@@ -0,0 +1,205 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Discussions pages read at most while looking for limit discussions of a
+// category, in repositories where it is rarely used.
+const maxDiscussionPages = 10
+
+const listDiscussionsQuery = `
+query($owner: String!, $name: String!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number
+        title
+        body
+        url
+        createdAt
+        upvoteCount
+        author { login }
+        category { name }
+        comments { totalCount }
+        reactions { totalCount }
+      }
+    }
+  }
+}`
+
+const discussionCommentsQuery = `
+query($owner: String!, $name: String!, $number: Int!, $first: Int!) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: $number) {
+      comments(first: $first) {
+        nodes {
+          id
+          body
+          url
+          createdAt
+          author { login }
+          reactions { totalCount }
+          replies(first: 20) {
+            nodes {
+              id
+              body
+              url
+              createdAt
+              author { login }
+              reactions { totalCount }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type graphqlActor struct {
+	Login string `json:"login"`
+}
+
+type graphqlCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+type graphqlComment struct {
+	ID        string       `json:"id"`
+	Body      string       `json:"body"`
+	URL       string       `json:"url"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Author    graphqlActor `json:"author"`
+	Reactions graphqlCount `json:"reactions"`
+	Replies   struct {
+		Nodes []graphqlComment `json:"nodes"`
+	} `json:"replies"`
+}
+
+// ListDiscussions returns the newest discussions of repo. A non-empty
+// category keeps only the discussions in it, e.g. "Ideas", and further
+// pages are read until limit of them are found.
+func (c *Client) ListDiscussions(ctx context.Context, repo, category string, limit int) ([]*Discussion, error) {
+	owner, name, err := splitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = perPage(limit)
+	var discussions []*Discussion
+	var after *string
+	for page := 0; page < maxDiscussionPages && len(discussions) < limit; page++ {
+		var data struct {
+			Repository struct {
+				Discussions struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						Number      int          `json:"number"`
+						Title       string       `json:"title"`
+						Body        string       `json:"body"`
+						URL         string       `json:"url"`
+						CreatedAt   time.Time    `json:"createdAt"`
+						UpvoteCount int          `json:"upvoteCount"`
+						Author      graphqlActor `json:"author"`
+						Category    struct {
+							Name string `json:"name"`
+						} `json:"category"`
+						Comments  graphqlCount `json:"comments"`
+						Reactions graphqlCount `json:"reactions"`
+					} `json:"nodes"`
+				} `json:"discussions"`
+			} `json:"repository"`
+		}
+		err = c.graphql(ctx, listDiscussionsQuery, map[string]any{
+			"owner": owner,
+			"name":  name,
+			"first": limit,
+			"after": after,
+		}, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, n := range data.Repository.Discussions.Nodes {
+			if category != "" && !strings.EqualFold(n.Category.Name, category) {
+				continue
+			}
+			discussions = append(discussions, &Discussion{
+				Number:    n.Number,
+				Title:     n.Title,
+				Body:      n.Body,
+				Author:    n.Author.Login,
+				URL:       n.URL,
+				Category:  n.Category.Name,
+				Reactions: n.UpvoteCount + n.Reactions.TotalCount,
+				Comments:  n.Comments.TotalCount,
+				CreatedAt: n.CreatedAt,
+			})
+			if len(discussions) == limit {
+				break
+			}
+		}
+
+		pageInfo := data.Repository.Discussions.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		after = &pageInfo.EndCursor
+	}
+	return discussions, nil
+}
+
+// ListDiscussionComments returns the first comments of a discussion, each
+// followed by its replies.
+func (c *Client) ListDiscussionComments(ctx context.Context, repo string, number, limit int) ([]*Comment, error) {
+	owner, name, err := splitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Repository struct {
+			Discussion struct {
+				Comments struct {
+					Nodes []graphqlComment `json:"nodes"`
+				} `json:"comments"`
+			} `json:"discussion"`
+		} `json:"repository"`
+	}
+	err = c.graphql(ctx, discussionCommentsQuery, map[string]any{
+		"owner":  owner,
+		"name":   name,
+		"number": number,
+		"first":  perPage(limit),
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []*Comment
+	for _, n := range data.Repository.Discussion.Comments.Nodes {
+		comments = append(comments, n.toComment(0))
+		for _, reply := range n.Replies.Nodes {
+			comments = append(comments, reply.toComment(1))
+		}
+	}
+	return comments, nil
+}
+
+func (n graphqlComment) toComment(depth int) *Comment {
+	return &Comment{
+		ID:        n.ID,
+		Body:      n.Body,
+		Author:    n.Author.Login,
+		URL:       n.URL,
+		Reactions: n.Reactions.TotalCount,
+		Depth:     depth,
+		CreatedAt: n.CreatedAt,
+	}
+}
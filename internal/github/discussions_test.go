@@ -0,0 +1,113 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// discussionPages fakes the GraphQL endpoint with two pages of discussions.
+// Only one discussion per page is in the Ideas category.
+func discussionPages(t *testing.T, requests *[]map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		*requests = append(*requests, body.Variables)
+
+		first, next, hasNext := 1, "cursor-1", true
+		if body.Variables["after"] == "cursor-1" {
+			first, next, hasNext = 3, "cursor-2", false
+		}
+		fmt.Fprintf(w, `{"data":{"repository":{"discussions":{
+  "pageInfo": {"hasNextPage": %t, "endCursor": %q},
+  "nodes": [
+    {"number": %d, "title": "Idea %d", "body": "body", "url": "https://github.com/acme/app/discussions/%d",
+     "createdAt": "2026-10-01T10:00:00Z", "upvoteCount": 4, "author": {"login": "alice"},
+     "category": {"name": "Ideas"}, "comments": {"totalCount": 2}, "reactions": {"totalCount": 1}},
+    {"number": %d, "title": "Question", "body": "body", "url": "https://github.com/acme/app/discussions/%d",
+     "createdAt": "2026-10-01T09:00:00Z", "upvoteCount": 0, "author": {"login": "bob"},
+     "category": {"name": "Q&A"}, "comments": {"totalCount": 0}, "reactions": {"totalCount": 0}}
+  ]}}}}`, hasNext, next, first, first, first, first+1, first+1)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestListDiscussionsPagesThroughCategory(t *testing.T) {
+	var requests []map[string]any
+	srv := discussionPages(t, &requests)
+
+	discussions, err := NewClient(srv.URL, "tok").ListDiscussions(context.Background(), "acme/app", "ideas", 2)
+	if err != nil {
+		t.Fatalf("ListDiscussions: %v", err)
+	}
+
+	if len(discussions) != 2 || discussions[0].Number != 1 || discussions[1].Number != 3 {
+		t.Fatalf("unexpected discussions %+v", discussions)
+	}
+	if d := discussions[0]; d.Category != "Ideas" || d.Reactions != 5 || d.Comments != 2 || d.Author != "alice" {
+		t.Fatalf("unexpected discussion %+v", d)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(requests))
+	}
+	if requests[0]["owner"] != "acme" || requests[0]["name"] != "app" || requests[0]["after"] != nil {
+		t.Fatalf("unexpected first page variables %v", requests[0])
+	}
+	if requests[1]["after"] != "cursor-1" {
+		t.Fatalf("second page after = %v, want cursor-1", requests[1]["after"])
+	}
+}
+
+func TestListDiscussionsStopsAtLimit(t *testing.T) {
+	var requests []map[string]any
+	srv := discussionPages(t, &requests)
+
+	discussions, err := NewClient(srv.URL, "tok").ListDiscussions(context.Background(), "acme/app", "", 2)
+	if err != nil {
+		t.Fatalf("ListDiscussions: %v", err)
+	}
+	if len(discussions) != 2 || len(requests) != 1 {
+		t.Fatalf("got %d discussions in %d requests, want 2 in 1", len(discussions), len(requests))
+	}
+}
+
+func TestListDiscussionsNeedsToken(t *testing.T) {
+	var requests []map[string]any
+	srv := discussionPages(t, &requests)
+
+	_, err := NewClient(srv.URL, "").ListDiscussions(context.Background(), "acme/app", "", 2)
+	if err == nil || !strings.Contains(err.Error(), "token") {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("made %d requests without a token", len(requests))
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":null,"errors":[{"message":"Could not resolve to a Repository"}]}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "tok").ListDiscussions(context.Background(), "acme/gone", "", 2)
+	if err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Fatalf("expected the GraphQL error, got %v", err)
+	}
+}
@@ -58,9 +58,12 @@ func (g *Groupper) ProcessSourceItems(ctx context.Context) error {
 			log.Printf("Failed to create problem: %v", err)
 		}
 
-		ideaId, err := g.createIdea(ctx, item.ID, analysisResult.Idea)
-		if err != nil {
-			log.Printf("Failed to create idea: %v", err)
+		var ideaId int
+		if hasIdea(item, analysisResult.Idea) {
+			ideaId, err = g.createIdea(ctx, item.ID, analysisResult.Idea)
+			if err != nil {
+				log.Printf("Failed to create idea: %v", err)
+			}
 		}
 
 		if problemId != 0 && ideaId != 0 {
@@ -75,7 +78,9 @@ func (g *Groupper) ProcessSourceItems(ctx context.Context) error {
 			if problemId != 0 && productId != 0 {
 				g.db.LinkProblemProduct(problemId, productId)
 			}
-			if ideaId != 0 && productId != 0 {
+			// An existing product is what the reviews complain about, the
+			// idea is a better take on it, not something it implements
+			if ideaId != 0 && productId != 0 && !p.Existing {
 				g.db.LinkIdeaProduct(ideaId, productId)
 			}
 		}
@@ -140,6 +145,13 @@ func (g *Groupper) createProblem(ctx context.Context, sourcId int, p analysis.An
 	return problemId, nil
 }
 
+// hasIdea tells whether the analysis of item holds an idea. Products-only
+// analyses, e.g. of sharing thread replies, leave it empty. Ideas scored 0
+// are still stored.
+func hasIdea(item *database.SourceItem, idea analysis.AnalysisResultIdea) bool {
+	return item.AnalysisMode != "products" && idea.Title != ""
+}
+
 func (g *Groupper) createIdea(ctx context.Context, sourceId int, analysisResult analysis.AnalysisResultIdea) (int, error) {
 	idea := &database.Idea{
 		Title:       analysisResult.Title,
@@ -161,6 +173,19 @@ func (g *Groupper) createIdea(ctx context.Context, sourceId int, analysisResult
 }
 
 func (g *Groupper) createProduct(ctx context.Context, sourceId int, analysisResult analysis.AnalysisResultProduct) (int, error) {
+	if analysisResult.Existing {
+		productId, err := g.db.FindProductByName(analysisResult.Name)
+		if err != nil {
+			return 0, err
+		}
+		if productId == 0 {
+			log.Printf("Existing product '%s' not found, not linking it", analysisResult.Name)
+			return 0, nil
+		}
+		g.db.UpdateSourceItemProductID([]int{sourceId}, productId)
+		return productId, nil
+	}
+
 	product := &database.Product{
 		Name:        analysisResult.Name,
 		Description: analysisResult.Description,
@@ -0,0 +1,32 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/letieu/idea-extractor/internal/analysis"
+	"github.com/letieu/idea-extractor/internal/database"
+)
+
+func TestHasIdea(t *testing.T) {
+	idea := analysis.AnalysisResultIdea{Title: "Uptime checker for indie apps"}
+	unscored := analysis.AnalysisResultIdea{Title: "Shared grocery lists", Score: 0}
+
+	tests := []struct {
+		name string
+		mode string
+		idea analysis.AnalysisResultIdea
+		want bool
+	}{
+		{"full analysis", "", idea, true},
+		{"scored 0", "", unscored, true},
+		{"review", "review", idea, true},
+		{"products only", "products", idea, false},
+		{"empty idea", "", analysis.AnalysisResultIdea{}, false},
+	}
+	for _, tt := range tests {
+		item := &database.SourceItem{AnalysisMode: tt.mode}
+		if got := hasIdea(item, tt.idea); got != tt.want {
+			t.Errorf("%s: hasIdea = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
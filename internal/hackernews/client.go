@@ -0,0 +1,172 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+)
+
+const defaultBaseURL = "https://hn.algolia.com/api/v1"
+
+// Client reads Hacker News through the Algolia search API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// Item is a story or comment. Text is plain text, the HTML of the API is
+// stripped.
+type Item struct {
+	ID          string
+	Title       string
+	Text        string
+	Author      string
+	URL         string // Link target of link stories
+	Points      int
+	NumComments int
+	CreatedAt   time.Time
+	ParentID    string
+	Children    []*Item
+}
+
+// SearchOptions selects stories from the search API.
+type SearchOptions struct {
+	Tags  string // Algolia tags, e.g. ask_hn, show_hn or story
+	Query string // Full-text query, optional
+	Limit int
+}
+
+type searchResponse struct {
+	Hits []struct {
+		ObjectID    string `json:"objectID"`
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Author      string `json:"author"`
+		Points      int    `json:"points"`
+		StoryText   string `json:"story_text"`
+		NumComments int    `json:"num_comments"`
+		CreatedAtI  int64  `json:"created_at_i"`
+	} `json:"hits"`
+}
+
+type itemResponse struct {
+	ID         int             `json:"id"`
+	Title      string          `json:"title"`
+	URL        string          `json:"url"`
+	Author     string          `json:"author"`
+	Points     int             `json:"points"`
+	Text       string          `json:"text"`
+	CreatedAtI int64           `json:"created_at_i"`
+	ParentID   int             `json:"parent_id"`
+	Children   []*itemResponse `json:"children"`
+}
+
+// NewClient creates a client for the Algolia HN API. An empty baseURL uses
+// the public endpoint.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+	}
+}
+
+// ItemURL returns the news.ycombinator.com page of an item.
+func ItemURL(id string) string {
+	return "https://news.ycombinator.com/item?id=" + id
+}
+
+// Search returns the newest stories matching opts, without comments.
+func (c *Client) Search(ctx context.Context, opts SearchOptions) ([]*Item, error) {
+	query := url.Values{}
+	if opts.Tags != "" {
+		query.Set("tags", opts.Tags)
+	}
+	if opts.Query != "" {
+		query.Set("query", opts.Query)
+	}
+	if opts.Limit > 0 {
+		query.Set("hitsPerPage", strconv.Itoa(opts.Limit))
+	}
+
+	var resp searchResponse
+	if err := c.get(ctx, "/search_by_date?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		items = append(items, &Item{
+			ID:          hit.ObjectID,
+			Title:       hit.Title,
+			Text:        htmltext.ToText(hit.StoryText),
+			Author:      hit.Author,
+			URL:         hit.URL,
+			Points:      hit.Points,
+			NumComments: hit.NumComments,
+			CreatedAt:   time.Unix(hit.CreatedAtI, 0),
+		})
+	}
+	return items, nil
+}
+
+// FetchItem returns an item with its whole comment tree.
+func (c *Client) FetchItem(ctx context.Context, id string) (*Item, error) {
+	var resp itemResponse
+	if err := c.get(ctx, "/items/"+url.PathEscape(id), &resp); err != nil {
+		return nil, err
+	}
+	return resp.toItem(), nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hacker news error %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *itemResponse) toItem() *Item {
+	item := &Item{
+		ID:        strconv.Itoa(r.ID),
+		Title:     r.Title,
+		Text:      htmltext.ToText(r.Text),
+		Author:    r.Author,
+		URL:       r.URL,
+		Points:    r.Points,
+		CreatedAt: time.Unix(r.CreatedAtI, 0),
+	}
+	if r.ParentID != 0 {
+		item.ParentID = strconv.Itoa(r.ParentID)
+	}
+	for _, child := range r.Children {
+		// Deleted comments come back without author and text
+		if child.Author == "" && child.Text == "" && len(child.Children) == 0 {
+			continue
+		}
+		item.Children = append(item.Children, child.toItem())
+	}
+	return item
+}
@@ -0,0 +1,104 @@
+package hackernews
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves the recorded Algolia responses in testdata and keeps
+// the query of the last search.
+func fixtureServer(t *testing.T, lastQuery *url.Values) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search_by_date", func(w http.ResponseWriter, r *http.Request) {
+		if lastQuery != nil {
+			*lastQuery = r.URL.Query()
+		}
+		http.ServeFile(w, r, "testdata/search_by_date.json")
+	})
+	mux.HandleFunc("/items/1001", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/item_1001.json")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSearch(t *testing.T) {
+	var query url.Values
+	srv := fixtureServer(t, &query)
+
+	items, err := NewClient(srv.URL).Search(context.Background(), SearchOptions{Tags: "ask_hn", Query: "tool", Limit: 2})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	if query.Get("tags") != "ask_hn" || query.Get("query") != "tool" || query.Get("hitsPerPage") != "2" {
+		t.Fatalf("unexpected query %v", query)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	ask := items[0]
+	if ask.ID != "1001" || ask.Author != "pg_fan" || ask.Points != 42 || ask.NumComments != 5 {
+		t.Fatalf("unexpected story %+v", ask)
+	}
+	if want := "Looking for gaps in the market.\n\nWhat do you keep rebuilding?"; ask.Text != want {
+		t.Fatalf("Text = %q, want %q", ask.Text, want)
+	}
+	if !ask.CreatedAt.Equal(time.Unix(1760000000, 0)) {
+		t.Fatalf("CreatedAt = %s", ask.CreatedAt)
+	}
+	if ask.URL != "" {
+		t.Fatalf("text story has URL %q", ask.URL)
+	}
+
+	show := items[1]
+	if show.URL != "https://invoices.example.com" || show.Text != "" {
+		t.Fatalf("unexpected link story %+v", show)
+	}
+}
+
+func TestFetchItemDropsDeletedLeaves(t *testing.T) {
+	srv := fixtureServer(t, nil)
+
+	story, err := NewClient(srv.URL).FetchItem(context.Background(), "1001")
+	if err != nil {
+		t.Fatalf("FetchItem: %v", err)
+	}
+
+	// 1006 is deleted without replies and dropped. 1004 is deleted too but
+	// kept so its reply is not lost.
+	var ids []string
+	for _, c := range story.Children {
+		ids = append(ids, c.ID)
+	}
+	if want := []string{"1002", "1004", "1007"}; !slices.Equal(ids, want) {
+		t.Fatalf("children = %v, want %v", ids, want)
+	}
+
+	first := story.Children[0]
+	if first.Text != "I'd pay for a simple uptime checker." || first.ParentID != "1001" {
+		t.Fatalf("unexpected comment %+v", first)
+	}
+	if len(first.Children) != 1 || first.Children[0].ID != "1003" {
+		t.Fatalf("reply of 1002 missing: %+v", first.Children)
+	}
+	if deleted := story.Children[1]; len(deleted.Children) != 1 || deleted.Children[0].Author != "carol" {
+		t.Fatalf("reply of deleted comment missing: %+v", deleted.Children)
+	}
+}
+
+func TestFetchItemError(t *testing.T) {
+	srv := fixtureServer(t, nil)
+
+	if _, err := NewClient(srv.URL).FetchItem(context.Background(), "404"); err == nil {
+		t.Fatal("expected an error for a missing item")
+	}
+}
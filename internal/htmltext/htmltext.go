@@ -0,0 +1,128 @@
+package htmltext
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\r\f\v]+`)
+	blankLines = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// ToText strips the markup from an HTML fragment and returns its readable
+// text. Block elements and <br> become line breaks, links keep their target
+// after the link text, and script and style content is dropped.
+func ToText(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := 0 // Depth inside script and style elements
+	pre := 0  // Depth inside pre elements, where line breaks are kept
+	var href string
+	var linkStart int
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return normalize(b.String())
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := string(z.Text())
+			if pre == 0 {
+				text = strings.ReplaceAll(text, "\n", " ")
+			}
+			b.WriteString(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript:
+				if tok.Type == html.StartTagToken {
+					skip++
+				}
+			case atom.Pre:
+				pre++
+				b.WriteString("\n")
+			case atom.Br:
+				b.WriteString("\n")
+			case atom.Li:
+				b.WriteString("\n- ")
+			case atom.A:
+				href = attr(tok, "href")
+				linkStart = b.Len()
+			default:
+				if isBlock(tok.DataAtom) {
+					b.WriteString("\n\n")
+				}
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript:
+				if skip > 0 {
+					skip--
+				}
+			case atom.Pre:
+				if pre > 0 {
+					pre--
+				}
+				b.WriteString("\n")
+			case atom.A:
+				// Skip the target when the link text already shows it,
+				// possibly shortened with an ellipsis
+				linkText := strings.TrimSuffix(strings.TrimSpace(b.String()[linkStart:]), "...")
+				if href != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, linkText) {
+					b.WriteString(" (" + href + ")")
+				}
+				href = ""
+			default:
+				if isBlock(tok.DataAtom) {
+					b.WriteString("\n\n")
+				}
+			}
+		}
+	}
+}
+
+// Headline returns the start of a text on one line, cut after max runes.
+// It titles content that has no title of its own, such as chat messages.
+func Headline(s string, max int) string {
+	line := strings.Join(strings.Fields(s), " ")
+	if r := []rune(line); len(r) > max {
+		line = string(r[:max]) + "..."
+	}
+	return line
+}
+
+func normalize(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaces.ReplaceAllString(line, " "))
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+func attr(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Blockquote, atom.Table, atom.Tr, atom.Hr:
+		return true
+	}
+	return false
+}
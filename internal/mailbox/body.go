@@ -0,0 +1,156 @@
+package mailbox
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"regexp"
+	"strings"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+	"golang.org/x/net/html/charset"
+)
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Lines that start the quoted part of a reply, everything below them is
+// the message being replied to.
+var replyHeaders = []*regexp.Regexp{
+	regexp.MustCompile(`^On .+ wrote:$`),
+	regexp.MustCompile(`^Le .+ a écrit ?:$`),
+	regexp.MustCompile(`^Am .+ schrieb .+:$`),
+	regexp.MustCompile(`^-+ ?Original Message ?-+$`),
+	regexp.MustCompile(`^-+ ?Forwarded message ?-+$`),
+	regexp.MustCompile(`^_{20,}$`),
+}
+
+// Lines that start a signature.
+var signatureStarts = []*regexp.Regexp{
+	regexp.MustCompile(`^--$`),
+	regexp.MustCompile(`^(?i)sent from my .+$`),
+	regexp.MustCompile(`^(?i)get outlook for .+$`),
+}
+
+func decodeHeader(s string) string {
+	decoded, err := wordDecoder.DecodeHeader(s)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// readBody returns the text of a message part. Multipart messages use their
+// text/plain alternative, or the text/html one converted to text.
+func readBody(contentType, encoding string, r io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	r = decodeTransfer(encoding, r)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var plain, html string
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+
+			text, err := readBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				continue
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			switch {
+			case partType == "text/html" && html == "":
+				html = text
+			case plain == "" && (partType == "" || partType == "text/plain" || strings.HasPrefix(partType, "multipart/")):
+				plain = text
+			}
+		}
+		if plain != "" {
+			return plain, nil
+		}
+		return html, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+
+	if cs := params["charset"]; cs != "" {
+		if decoded, err := charset.NewReaderLabel(cs, r); err == nil {
+			r = decoded
+		}
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if mediaType == "text/html" {
+		return htmltext.ToText(string(raw)), nil
+	}
+	return string(raw), nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return r
+}
+
+// stripReply keeps what the sender wrote: quoted lines, the message being
+// replied to and the signature are dropped.
+func stripReply(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	var kept []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		if isReplyHeader(trimmed, lines[i+1:]) || matchAnyLine(signatureStarts, trimmed) {
+			break
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func isReplyHeader(line string, rest []string) bool {
+	if matchAnyLine(replyHeaders, line) {
+		return true
+	}
+	// Outlook puts a From/Sent header block above the quoted message
+	if strings.HasPrefix(line, "From: ") && len(rest) > 0 {
+		next := strings.TrimSpace(rest[0])
+		return strings.HasPrefix(next, "Sent: ") || strings.HasPrefix(next, "Date: ")
+	}
+	return false
+}
+
+func matchAnyLine(patterns []*regexp.Regexp, line string) bool {
+	for _, re := range patterns {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
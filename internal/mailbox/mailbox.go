@@ -0,0 +1,271 @@
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email with its body reduced to the text the sender wrote.
+type Message struct {
+	ID         string // Message-ID without angle brackets
+	InReplyTo  string
+	References []string
+	Subject    string
+	From       string
+	Date       time.Time
+	Body       string
+}
+
+// Thread is a root message and its replies, oldest first.
+type Thread struct {
+	ID       string // Message-ID of the root, or of the message the thread replies to
+	Messages []*Message
+}
+
+// Load reads a Maildir directory (with cur and new folders) or an mbox
+// file. Messages with the same Message-ID are only returned once.
+func Load(path string) ([]*Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws [][]byte
+	if info.IsDir() {
+		raws, err = readMaildir(path)
+	} else {
+		raws, err = readMbox(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	messages := make([]*Message, 0, len(raws))
+	for _, raw := range raws {
+		m, err := parse(raw)
+		if err != nil {
+			continue
+		}
+		if seen[m.ID] {
+			continue
+		}
+		seen[m.ID] = true
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// Group threads messages by In-Reply-To and References. Replies whose root
+// is not in the mailbox are grouped under the Message-ID they reply to.
+func Group(messages []*Message) []*Thread {
+	byID := make(map[string]*Message, len(messages))
+	for _, m := range messages {
+		byID[m.ID] = m
+	}
+
+	var root func(m *Message, depth int) string
+	root = func(m *Message, depth int) string {
+		if len(m.References) > 0 {
+			return m.References[0]
+		}
+		if m.InReplyTo == "" {
+			return m.ID
+		}
+		parent, ok := byID[m.InReplyTo]
+		if !ok || depth > 100 {
+			return m.InReplyTo
+		}
+		return root(parent, depth+1)
+	}
+
+	var threads []*Thread
+	byRoot := map[string]*Thread{}
+	for _, m := range messages {
+		key := root(m, 0)
+		t, ok := byRoot[key]
+		if !ok {
+			t = &Thread{ID: key}
+			byRoot[key] = t
+			threads = append(threads, t)
+		}
+		t.Messages = append(t.Messages, m)
+	}
+
+	for _, t := range threads {
+		sort.SliceStable(t.Messages, func(i, j int) bool {
+			return t.Messages[i].Date.Before(t.Messages[j].Date)
+		})
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Messages[0].Date.Before(threads[j].Messages[0].Date)
+	})
+	return threads
+}
+
+// Subject is the subject of the first message without reply prefixes.
+func (t *Thread) Subject() string {
+	subject := t.Messages[0].Subject
+	for {
+		trimmed := strings.TrimSpace(subject)
+		lower := strings.ToLower(trimmed)
+		for _, prefix := range []string{"re:", "fwd:", "fw:", "aw:", "sv:"} {
+			if strings.HasPrefix(lower, prefix) {
+				trimmed = trimmed[len(prefix):]
+				break
+			}
+		}
+		if trimmed == strings.TrimSpace(subject) {
+			return trimmed
+		}
+		subject = trimmed
+	}
+}
+
+func readMaildir(dir string) ([][]byte, error) {
+	var raws [][]byte
+	found := false
+	for _, sub := range []string{"cur", "new"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			raw, err := os.ReadFile(filepath.Join(dir, sub, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			raws = append(raws, raw)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not a Maildir, it has no cur or new folder", dir)
+	}
+	return raws, nil
+}
+
+// readMbox splits an mbox file on its "From " separator lines and undoes
+// the ">From " quoting of mboxrd files.
+func readMbox(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var raws [][]byte
+	var cur *bytes.Buffer
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case bytes.HasPrefix(line, []byte("From ")):
+				if cur != nil {
+					raws = append(raws, cur.Bytes())
+				}
+				cur = &bytes.Buffer{}
+			case cur == nil:
+				// Garbage before the first separator
+			case isQuotedFrom(line):
+				cur.Write(line[1:])
+			default:
+				cur.Write(line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cur != nil {
+		raws = append(raws, cur.Bytes())
+	}
+	if len(raws) == 0 {
+		return nil, fmt.Errorf("%s is not an mbox file", path)
+	}
+	return raws, nil
+}
+
+func isQuotedFrom(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) && line[0] == '>'
+}
+
+func parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	h := msg.Header
+
+	m := &Message{
+		ID:         messageID(h.Get("Message-Id")),
+		InReplyTo:  messageID(h.Get("In-Reply-To")),
+		References: messageIDs(h.Get("References")),
+		Subject:    decodeHeader(h.Get("Subject")),
+		From:       sender(h.Get("From")),
+	}
+	m.Date, _ = h.Date()
+
+	body, err := readBody(h.Get("Content-Type"), h.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.Body = stripReply(body)
+
+	if m.ID == "" {
+		sum := sha1.Sum([]byte(h.Get("From") + "\x00" + h.Get("Date") + "\x00" + h.Get("Subject")))
+		m.ID = hex.EncodeToString(sum[:]) + "@generated"
+	}
+	return m, nil
+}
+
+func messageID(s string) string {
+	ids := messageIDs(s)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// messageIDs reads a list of <id> tokens, as in References.
+func messageIDs(s string) []string {
+	var ids []string
+	for _, field := range strings.Fields(s) {
+		id := strings.Trim(field, "<>,")
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func sender(s string) string {
+	addr, err := mail.ParseAddress(decodeHeader(s))
+	if err != nil {
+		return decodeHeader(s)
+	}
+	if addr.Name != "" {
+		return addr.Name
+	}
+	return addr.Address
+}
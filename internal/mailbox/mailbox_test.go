@@ -0,0 +1,132 @@
+package mailbox
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadMbox(t *testing.T) {
+	messages, err := Load("testdata/thread.mbox")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages, want 4", len(messages))
+	}
+
+	root := messages[0]
+	if root.ID != "root@example.com" || root.From != "Alice Martin" {
+		t.Fatalf("unexpected root %+v", root)
+	}
+	// The mboxrd ">From " quoting is undone and the signature dropped
+	want := "Every month I spend hours copying hours from my time tracker into invoices.\nFrom what I can tell nothing does it automatically."
+	if root.Body != want {
+		t.Fatalf("Body = %q, want %q", root.Body, want)
+	}
+
+	last := messages[2]
+	if last.From != "Carol Bérard" || !slices.Equal(last.References, []string{"root@example.com", "r1@example.com"}) {
+		t.Fatalf("unexpected reply %+v", last)
+	}
+}
+
+func TestLoadMaildir(t *testing.T) {
+	messages, err := Load("testdata/maildir")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// The copy of the root in cur has the same Message-ID and is dropped
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+
+	if got := messages[0].Body; got != "Our onboarding emails get ignored." {
+		t.Fatalf("Body = %q, want the text/plain alternative", got)
+	}
+	if got := messages[1].Body; got != "Try a drip sequence with a clear “first step”." {
+		t.Fatalf("Body = %q, want the quoted-printable text without the Outlook header", got)
+	}
+}
+
+func TestLoadRejectsOtherPaths(t *testing.T) {
+	if _, err := Load("testdata"); err == nil {
+		t.Fatal("expected an error for a directory that is not a Maildir")
+	}
+	if _, err := Load("testdata/missing.mbox"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	messages, err := Load("testdata/thread.mbox")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	threads := Group(messages)
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want 2", len(threads))
+	}
+
+	// r1 only has In-Reply-To, r2 has References, both join the root
+	var ids []string
+	for _, m := range threads[0].Messages {
+		ids = append(ids, m.ID)
+	}
+	if threads[0].ID != "root@example.com" || !slices.Equal(ids, []string{"root@example.com", "r1@example.com", "r2@example.com"}) {
+		t.Fatalf("thread %s holds %v", threads[0].ID, ids)
+	}
+	if got := threads[0].Subject(); got != "Looking for a better invoicing tool" {
+		t.Fatalf("Subject = %q", got)
+	}
+
+	// A reply whose root is missing is grouped under the message it replies to
+	if threads[1].ID != "missing@example.com" || threads[1].Subject() != "Pricing page feedback" {
+		t.Fatalf("unexpected orphan thread %s %q", threads[1].ID, threads[1].Subject())
+	}
+}
+
+func TestGroupOrdersByDate(t *testing.T) {
+	messages, err := Load("testdata/maildir")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// Newest first on purpose, Group sorts threads and messages by date
+	slices.Reverse(messages)
+
+	threads := Group(messages)
+	if len(threads) != 1 || threads[0].ID != "mroot@example.com" {
+		t.Fatalf("unexpected threads %+v", threads)
+	}
+	if first := threads[0].Messages[0]; first.ID != "mroot@example.com" {
+		t.Fatalf("first message = %s, want the root", first.ID)
+	}
+	if got := threads[0].Subject(); got != "Onboarding emails" {
+		t.Fatalf("Subject = %q", got)
+	}
+}
+
+func TestStripReply(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"plain", "Hello\r\nthere\r\n", "Hello\nthere"},
+		{"quoted lines", "> earlier\nMy answer\n  >> older", "My answer"},
+		{"english header", "Agreed.\n\nOn Mon, 5 Oct 2026, Alice wrote:\nNot quoted but replied to", "Agreed."},
+		{"french header", "D'accord.\nLe lun. 5 oct. 2026, Alice a écrit :\nsuite", "D'accord."},
+		{"german header", "Ja.\nAm 05.10.2026 schrieb Alice <a@example.com>:\nmehr", "Ja."},
+		{"original message", "See below\n-----Original Message-----\nFrom: x", "See below"},
+		{"outlook block", "Thanks\n\nFrom: Alice\nSent: Monday\nSubject: x", "Thanks"},
+		{"from line alone", "From: the team\nwith love", "From: the team\nwith love"},
+		{"signature", "Body\n-- \nAlice\nCEO", "Body"},
+		{"mobile signature", "Short reply\n\nSent from my iPhone", "Short reply"},
+		{"outlook mobile", "Ok\nGet Outlook for Android", "Ok"},
+	}
+	for _, tt := range tests {
+		if got := stripReply(tt.body); got != tt.want {
+			t.Errorf("%s: stripReply = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+)
+
+// Client reads public statuses from one Mastodon instance through its REST
+// API. Some instances only serve timelines to authenticated clients, the
+// token is optional otherwise.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// Status is a post with its content converted to plain text.
+type Status struct {
+	ID          string // Local to the instance the status was read from
+	URI         string // Global ActivityPub ID, the same on every instance
+	URL         string
+	InReplyToID string
+	Author      string // acct, e.g. alice@mastodon.social
+	Content     string
+	Sensitive   bool
+	Replies     int
+	Favourites  int
+	Reblogs     int
+	CardURL     string // Link previewed under the status
+	CreatedAt   time.Time
+}
+
+// Context is the thread around a status, as returned by /context.
+type Context struct {
+	Ancestors   []*Status // Oldest first, the root is the first one
+	Descendants []*Status // Replies in thread order
+}
+
+type apiStatus struct {
+	ID          string  `json:"id"`
+	URI         string  `json:"uri"`
+	URL         string  `json:"url"`
+	InReplyToID *string `json:"in_reply_to_id"`
+	Account     struct {
+		Acct string `json:"acct"`
+	} `json:"account"`
+	Content         string     `json:"content"`
+	SpoilerText     string     `json:"spoiler_text"`
+	Sensitive       bool       `json:"sensitive"`
+	RepliesCount    int        `json:"replies_count"`
+	FavouritesCount int        `json:"favourites_count"`
+	ReblogsCount    int        `json:"reblogs_count"`
+	Reblog          *apiStatus `json:"reblog"`
+	Card            *struct {
+		URL string `json:"url"`
+	} `json:"card"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Mention and hashtag links would add the profile or tag URL after every
+// name, they are turned into plain text.
+var mentionLink = regexp.MustCompile(`<a [^>]*class="[^"]*\b(mention|hashtag)\b[^"]*"[^>]*>`)
+
+// NewClient creates a client for instance, a host name such as
+// mastodon.social or a base URL.
+func NewClient(instance, token string) *Client {
+	baseURL := instance
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+	}
+}
+
+// HashtagTimeline returns the newest statuses tagged with hashtag, without
+// the leading #. Boosts are resolved to the boosted status.
+func (c *Client) HashtagTimeline(ctx context.Context, hashtag string, limit int) ([]*Status, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(pageSize(limit)))
+
+	var resp []apiStatus
+	if err := c.get(ctx, "/api/v1/timelines/tag/"+url.PathEscape(strings.TrimPrefix(hashtag, "#")), query, &resp); err != nil {
+		return nil, err
+	}
+	return toStatuses(resp), nil
+}
+
+// AccountStatuses returns the newest statuses of acct, e.g.
+// alice@mastodon.social, leaving out boosts and replies to others.
+func (c *Client) AccountStatuses(ctx context.Context, acct string, limit int) ([]*Status, error) {
+	lookup := url.Values{}
+	lookup.Set("acct", strings.TrimPrefix(acct, "@"))
+
+	var account struct {
+		ID string `json:"id"`
+	}
+	if err := c.get(ctx, "/api/v1/accounts/lookup", lookup, &account); err != nil {
+		return nil, fmt.Errorf("failed to look up account %s: %w", acct, err)
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(pageSize(limit)))
+	query.Set("exclude_reblogs", "true")
+	query.Set("exclude_replies", "true")
+
+	var resp []apiStatus
+	if err := c.get(ctx, "/api/v1/accounts/"+account.ID+"/statuses", query, &resp); err != nil {
+		return nil, err
+	}
+	return toStatuses(resp), nil
+}
+
+// Context returns the ancestors and replies of a status.
+func (c *Client) Context(ctx context.Context, id string) (*Context, error) {
+	var resp struct {
+		Ancestors   []apiStatus `json:"ancestors"`
+		Descendants []apiStatus `json:"descendants"`
+	}
+	if err := c.get(ctx, "/api/v1/statuses/"+id+"/context", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &Context{
+		Ancestors:   toStatuses(resp.Ancestors),
+		Descendants: toStatuses(resp.Descendants),
+	}, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("mastodon error %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func toStatuses(resp []apiStatus) []*Status {
+	statuses := make([]*Status, 0, len(resp))
+	for _, r := range resp {
+		if r.Reblog != nil {
+			r = *r.Reblog
+		}
+
+		content := htmltext.ToText(mentionLink.ReplaceAllString(r.Content, "<a>"))
+		if r.SpoilerText != "" {
+			content = "CW: " + r.SpoilerText + "\n" + content
+		}
+
+		s := &Status{
+			ID:         r.ID,
+			URI:        r.URI,
+			URL:        r.URL,
+			Author:     r.Account.Acct,
+			Content:    content,
+			Sensitive:  r.Sensitive,
+			Replies:    r.RepliesCount,
+			Favourites: r.FavouritesCount,
+			Reblogs:    r.ReblogsCount,
+			CreatedAt:  r.CreatedAt,
+		}
+		if r.InReplyToID != nil {
+			s.InReplyToID = *r.InReplyToID
+		}
+		if r.Card != nil {
+			s.CardURL = r.Card.URL
+		}
+		if s.URL == "" {
+			s.URL = s.URI
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// Mastodon pages hold at most 40 statuses.
+func pageSize(limit int) int {
+	if limit <= 0 || limit > 40 {
+		return 40
+	}
+	return limit
+}
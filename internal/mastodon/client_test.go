@@ -0,0 +1,115 @@
+package mastodon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const hashtagJSON = `[
+  {"id": "101", "uri": "https://mastodon.social/users/alice/statuses/101", "url": "https://mastodon.social/@alice/101",
+   "in_reply_to_id": null, "account": {"acct": "alice"},
+   "content": "<p>Shipping my <a href=\"https://mastodon.social/tags/buildinpublic\" class=\"mention hashtag\" rel=\"tag\">#<span>buildinpublic</span></a> invoice tool</p>",
+   "spoiler_text": "", "sensitive": false, "replies_count": 2, "favourites_count": 5, "reblogs_count": 1,
+   "reblog": null, "card": {"url": "https://invoices.example.com"}, "created_at": "2026-10-01T10:00:00.000Z"},
+  {"id": "102", "uri": "https://example.social/users/bob/statuses/9", "url": "", "in_reply_to_id": null, "account": {"acct": "carol"},
+   "content": "", "created_at": "2026-10-01T11:00:00.000Z",
+   "reblog": {"id": "103", "uri": "https://example.social/users/bob/statuses/9", "url": "", "in_reply_to_id": "100",
+     "account": {"acct": "bob@example.social"}, "content": "<p>Anyone else tired of spreadsheets?</p>",
+     "spoiler_text": "rant", "sensitive": true, "replies_count": 0, "favourites_count": 3, "reblogs_count": 4,
+     "created_at": "2026-10-01T09:00:00.000Z"}}
+]`
+
+func TestHashtagTimeline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/timelines/tag/buildinpublic" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("limit"); got != "20" {
+			t.Errorf("limit = %q, want 20", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(hashtagJSON))
+	}))
+	defer srv.Close()
+
+	statuses, err := NewClient(srv.URL, "tok").HashtagTimeline(context.Background(), "#buildinpublic", 20)
+	if err != nil {
+		t.Fatalf("HashtagTimeline: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+
+	own := statuses[0]
+	if own.Content != "Shipping my #buildinpublic invoice tool" {
+		t.Fatalf("Content = %q, want the hashtag link as plain text", own.Content)
+	}
+	if own.Author != "alice" || own.Replies != 2 || own.Favourites != 5 || own.CardURL != "https://invoices.example.com" || own.InReplyToID != "" {
+		t.Fatalf("unexpected status %+v", own)
+	}
+
+	// Boosts are replaced by the boosted status
+	boost := statuses[1]
+	if boost.ID != "103" || boost.Author != "bob@example.social" || boost.InReplyToID != "100" || !boost.Sensitive {
+		t.Fatalf("boost not resolved: %+v", boost)
+	}
+	if boost.Content != "CW: rant\nAnyone else tired of spreadsheets?" {
+		t.Fatalf("Content = %q", boost.Content)
+	}
+	if boost.URL != boost.URI {
+		t.Fatalf("URL = %q, want the URI when the status has no URL", boost.URL)
+	}
+}
+
+func TestAccountStatuses(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v1/accounts/lookup":
+			if q.Get("acct") != "alice@mastodon.social" {
+				t.Errorf("acct = %q", q.Get("acct"))
+			}
+			w.Write([]byte(`{"id": "42", "acct": "alice@mastodon.social"}`))
+		case "/api/v1/accounts/42/statuses":
+			if q.Get("exclude_reblogs") != "true" || q.Get("exclude_replies") != "true" || q.Get("limit") != "40" {
+				t.Errorf("unexpected query %v", q)
+			}
+			w.Write([]byte(`[{"id": "7", "uri": "https://mastodon.social/users/alice/statuses/7", "url": "https://mastodon.social/@alice/7",
+  "account": {"acct": "alice"}, "content": "<p>New release</p>", "created_at": "2026-10-01T10:00:00.000Z"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	statuses, err := NewClient(srv.URL, "").AccountStatuses(context.Background(), "@alice@mastodon.social", 0)
+	if err != nil {
+		t.Fatalf("AccountStatuses: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].ID != "7" || statuses[0].Content != "New release" {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("made requests %v, want the lookup and the statuses", paths)
+	}
+}
+
+func TestAccountStatusesUnknownAccount(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Record not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "").AccountStatuses(context.Background(), "ghost@mastodon.social", 10)
+	if err == nil || !strings.Contains(err.Error(), "failed to look up account ghost@mastodon.social") {
+		t.Fatalf("expected a lookup error, got %v", err)
+	}
+}
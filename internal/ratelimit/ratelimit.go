@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces requests at least an interval apart. Callers reserve the
+// next slot under a lock, so it is safe for concurrent use and goroutines
+// are served in the order they asked.
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // Earliest time the next request may start
+}
+
+// New creates a limiter that lets a request through every interval. A zero
+// interval only applies the pauses.
+func New(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// PerMinute creates a limiter allowing rpm requests a minute, spaced
+// evenly. It returns nil, which never waits, when rpm is not positive.
+func PerMinute(rpm int) *Limiter {
+	if rpm <= 0 {
+		return nil
+	}
+	return New(time.Minute / time.Duration(rpm))
+}
+
+// Wait reserves the next slot and blocks until it comes.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Pause holds every request for at least d from now.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if at := time.Now().Add(d); at.After(l.next) {
+		l.next = at
+	}
+}
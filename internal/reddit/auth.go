@@ -0,0 +1,94 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	fhttp "github.com/bogdanfinn/fhttp"
+	tls_client "github.com/bogdanfinn/tls-client"
+)
+
+// Tokens are refreshed this long before Reddit says they expire.
+const tokenRefreshMargin = time.Minute
+
+// tokenSource fetches and caches app-only OAuth bearer tokens using the
+// client credentials grant.
+type tokenSource struct {
+	httpClient   tls_client.HttpClient
+	url          string
+	clientID     string
+	clientSecret string
+	userAgent    string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Error       string `json:"error"`
+}
+
+// Token returns a valid access token, requesting a new one when the cached
+// token is missing or about to expire.
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := fhttp.NewRequestWithContext(ctx, "POST", t.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(t.clientID, t.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", t.userAgent)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request reddit token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("reddit token error %d: %s", resp.StatusCode, body)
+	}
+
+	var parsed tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("failed to decode reddit token: %w", err)
+	}
+	if parsed.Error != "" {
+		return "", fmt.Errorf("reddit token error: %s", parsed.Error)
+	}
+	if parsed.AccessToken == "" {
+		return "", fmt.Errorf("reddit returned an empty access token")
+	}
+
+	t.token = parsed.AccessToken
+	t.expiresAt = time.Now().Add(time.Duration(parsed.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// Invalidate drops the cached token so the next call fetches a new one.
+func (t *tokenSource) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
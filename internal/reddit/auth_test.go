@@ -0,0 +1,139 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// oauthServer fakes the Reddit token endpoint and a subreddit listing. Each
+// token it hands out is numbered and lives expiresIn seconds.
+type oauthServer struct {
+	*httptest.Server
+	expiresIn   int
+	reject      atomic.Bool // Answer the next listing with a 401
+	tokens      atomic.Int32
+	listings    atomic.Int32
+	lastAuth    atomic.Value
+	lastTokenUA atomic.Value
+}
+
+func newOAuthServer(t *testing.T, expiresIn int) *oauthServer {
+	t.Helper()
+	s := &oauthServer{expiresIn: expiresIn}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if r.Method != http.MethodPost || !ok || id != "id" || secret != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+			return
+		}
+		s.lastTokenUA.Store(r.UserAgent())
+		n := s.tokens.Add(1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, s.expiresIn)
+	})
+	mux.HandleFunc("/r/golang/new.json", func(w http.ResponseWriter, r *http.Request) {
+		s.listings.Add(1)
+		s.lastAuth.Store(r.Header.Get("Authorization"))
+		if s.reject.CompareAndSwap(true, false) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(listingJSON))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *oauthServer) client(t *testing.T) *RedditClient {
+	t.Helper()
+	client, err := NewClient(Options{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		UserAgent:    "idea-extractor-test",
+		BaseURL:      s.URL,
+		TokenURL:     s.URL + "/api/v1/access_token",
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestOAuthTokenIsFetchedOnceAndSent(t *testing.T) {
+	srv := newOAuthServer(t, 3600)
+	client := srv.client(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err != nil {
+			t.Fatalf("FetchListing: %v", err)
+		}
+	}
+
+	if got := srv.tokens.Load(); got != 1 {
+		t.Fatalf("fetched %d tokens, want 1", got)
+	}
+	if got := srv.listings.Load(); got != 3 {
+		t.Fatalf("served %d listings, want 3", got)
+	}
+	if got := srv.lastAuth.Load(); got != "Bearer token-1" {
+		t.Fatalf("Authorization header = %q, want %q", got, "Bearer token-1")
+	}
+	if got := srv.lastTokenUA.Load(); got != "idea-extractor-test" {
+		t.Fatalf("token request User-Agent = %q", got)
+	}
+}
+
+func TestOAuthTokenIsRefreshedBeforeExpiry(t *testing.T) {
+	// A token that expires within the refresh margin is never reused.
+	srv := newOAuthServer(t, int(tokenRefreshMargin.Seconds())-1)
+	client := srv.client(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err != nil {
+			t.Fatalf("FetchListing: %v", err)
+		}
+	}
+
+	if got := srv.tokens.Load(); got != 2 {
+		t.Fatalf("fetched %d tokens, want 2", got)
+	}
+	if got := srv.lastAuth.Load(); got != "Bearer token-2" {
+		t.Fatalf("Authorization header = %q, want %q", got, "Bearer token-2")
+	}
+}
+
+func TestOAuthTokenIsDroppedOnUnauthorized(t *testing.T) {
+	srv := newOAuthServer(t, 3600)
+	srv.reject.Store(true)
+	client := srv.client(t)
+	ctx := context.Background()
+
+	if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err == nil {
+		t.Fatal("expected an error for a 401")
+	}
+	if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err != nil {
+		t.Fatalf("FetchListing: %v", err)
+	}
+	if got := srv.lastAuth.Load(); got != "Bearer token-2" {
+		t.Fatalf("Authorization header = %q, want a fresh token", got)
+	}
+}
+
+func TestOAuthRequiresCredentials(t *testing.T) {
+	if _, err := NewClient(Options{ClientID: "id"}); err == nil {
+		t.Fatal("expected an error without a client secret")
+	}
+}
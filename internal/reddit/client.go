@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	fhttp "github.com/bogdanfinn/fhttp"
@@ -13,10 +17,52 @@ import (
 )
 
 type RedditClient struct {
-	httpClient tls_client.HttpClient
-	userAgent  string
+	httpClient  tls_client.HttpClient
+	userAgent   string
+	baseURL     string
+	token       *tokenSource // nil in anonymous mode
+	limiter     *rateLimiter
+	maxRetries  int
+	backoffBase time.Duration
 }
 
+// Options configures a RedditClient.
+type Options struct {
+	ClientID     string
+	ClientSecret string
+	UserAgent    string
+
+	// Anonymous scrapes the public JSON endpoints of www.reddit.com with a
+	// browser TLS fingerprint instead of using app-only OAuth.
+	Anonymous bool
+
+	// MinInterval is the minimum delay between two requests. Reddit's own
+	// rate-limit headers can slow the client down further.
+	MinInterval time.Duration
+	// MaxRetries bounds the retries of throttled and 5xx responses,
+	// defaults to 5. Use a negative value to disable retries.
+	MaxRetries int
+	// BackoffBase is the delay before the first retry, doubled for every
+	// following one. Defaults to 2s.
+	BackoffBase time.Duration
+
+	// BaseURL and TokenURL override the Reddit endpoints, e.g. to point the
+	// client at a local fake server.
+	BaseURL  string
+	TokenURL string
+}
+
+const (
+	oauthBaseURL     = "https://oauth.reddit.com"
+	anonymousBaseURL = "https://www.reddit.com"
+	tokenURL         = "https://www.reddit.com/api/v1/access_token"
+
+	defaultMaxRetries = 5
+
+	defaultOAuthUserAgent = "idea-extractor/1.0"
+	browserUserAgent      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
 type Post struct {
 	ID        string
 	Title     string
@@ -26,13 +72,63 @@ type Post struct {
 	URL       string
 	Score     int
 	CreatedAt time.Time
+
+	// Set on posts only
+	NumComments          int
+	UpvoteRatio          float64
+	Flair                string
+	NSFW                 bool
+	IsSelf               bool
+	Stickied             bool
+	EditedAt             time.Time // Zero if never edited
+	ExternalURL          string    // Link target of link posts
+	SubredditSubscribers int
+
+	// Set on comments only
+	ParentID string // Fullname of the parent, t3_ for top-level comments
+	Depth    int    // 0 for top-level comments
+}
+
+// ListingOptions selects a page of a subreddit listing.
+type ListingOptions struct {
+	Sort   string // new (default), hot, top, rising or search
+	Time   string // Time window for top and search, e.g. week or year
+	Query  string // Search query, used with the search sort
+	Limit  int    // Max posts to return, may span several pages
+	After  string // Fullname cursor, fetch posts older than this one
+	Before string // Fullname cursor, fetch posts newer than this one
+}
+
+// Listing is a single page of posts with the cursors around it.
+type Listing struct {
+	Posts  []*Post
+	After  string
+	Before string
+}
+
+// CommentOptions bounds the size of the comment tree returned by FetchComments.
+type CommentOptions struct {
+	MaxDepth    int // Levels of comments to keep, 1 keeps top-level only, 0 means no limit
+	MaxComments int // Max comments to return, 0 means no limit
+}
+
+// Reddit never returns more than 100 items per listing page, and expands at
+// most 100 collapsed comments per morechildren call.
+const (
+	maxPageSize     = 100
+	maxMoreChildren = 100
+)
+
+type redditThing struct {
+	Kind string     `json:"kind"`
+	Data redditPost `json:"data"`
 }
 
 type redditListingResponse struct {
 	Data struct {
-		Children []struct {
-			Data redditPost `json:"data"`
-		} `json:"children"`
+		Children []redditThing `json:"children"`
+		After    string        `json:"after"`
+		Before   string        `json:"before"`
 	} `json:"data"`
 }
 
@@ -47,10 +143,37 @@ type redditPost struct {
 	Permalink  string  `json:"permalink"`
 	Score      int     `json:"score"`
 	CreatedUTC float64 `json:"created_utc"`
+
+	NumComments          int             `json:"num_comments"`
+	UpvoteRatio          float64         `json:"upvote_ratio"`
+	LinkFlairText        string          `json:"link_flair_text"`
+	Over18               bool            `json:"over_18"`
+	IsSelf               bool            `json:"is_self"`
+	Stickied             bool            `json:"stickied"`
+	Edited               json.RawMessage `json:"edited"` // false or a timestamp
+	SubredditSubscribers int             `json:"subreddit_subscribers"`
+
+	// Comment fields
+	ParentID string          `json:"parent_id"`
+	Depth    int             `json:"depth"`
+	Replies  json.RawMessage `json:"replies"` // "" or a listing
+
+	// "more" stub fields
+	Children []string `json:"children"`
 }
 
-// NewClient using public Reddit API
-func NewClient() (*RedditClient, error) {
+type redditMoreChildrenResponse struct {
+	JSON struct {
+		Errors [][]any `json:"errors"`
+		Data   struct {
+			Things []redditThing `json:"things"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// NewClient creates a Reddit client. By default it authenticates with the
+// app-only OAuth flow; opts.Anonymous falls back to the public JSON pages.
+func NewClient(opts Options) (*RedditClient, error) {
 	options := []tls_client.HttpClientOption{
 		tls_client.WithTimeoutSeconds(30),
 		tls_client.WithClientProfile(profiles.Chrome_120),
@@ -61,45 +184,191 @@ func NewClient() (*RedditClient, error) {
 		return nil, err
 	}
 
-	return &RedditClient{
-		httpClient: client,
-		userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-	}, nil
+	r := &RedditClient{
+		httpClient:  client,
+		userAgent:   opts.UserAgent,
+		baseURL:     opts.BaseURL,
+		limiter:     newRateLimiter(opts.MinInterval),
+		maxRetries:  opts.MaxRetries,
+		backoffBase: opts.BackoffBase,
+	}
+	if r.maxRetries == 0 {
+		r.maxRetries = defaultMaxRetries
+	}
+	if r.backoffBase == 0 {
+		r.backoffBase = defaultBackoffBase
+	}
+
+	if opts.Anonymous {
+		if r.userAgent == "" {
+			r.userAgent = browserUserAgent
+		}
+		if r.baseURL == "" {
+			r.baseURL = anonymousBaseURL
+		}
+		return r, nil
+	}
+
+	if opts.ClientID == "" || opts.ClientSecret == "" {
+		return nil, fmt.Errorf("reddit oauth requires a client id and secret")
+	}
+	if r.userAgent == "" {
+		r.userAgent = defaultOAuthUserAgent
+	}
+	if r.baseURL == "" {
+		r.baseURL = oauthBaseURL
+	}
+	r.token = &tokenSource{
+		httpClient:   client,
+		url:          opts.TokenURL,
+		clientID:     opts.ClientID,
+		clientSecret: opts.ClientSecret,
+		userAgent:    r.userAgent,
+	}
+	if r.token.url == "" {
+		r.token.url = tokenURL
+	}
+
+	return r, nil
 }
 
-// Fetch subreddit posts using public JSON
-func (r *RedditClient) FetchPosts(ctx context.Context, subreddit string, limit int) ([]*Post, error) {
-	url := fmt.Sprintf(
-		"https://www.reddit.com/r/%s/new.json?limit=%d",
-		subreddit, limit,
-	)
+// get requests a Reddit JSON endpoint and decodes the response into out.
+// Requests go through the shared rate limiter and are retried with backoff
+// when Reddit throttles them or fails with a 5xx.
+func (r *RedditClient) get(ctx context.Context, path string, query url.Values, out any) error {
+	reqURL := r.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		resp, err := r.do(ctx, reqURL)
+		if err != nil {
+			return err
+		}
+		r.limiter.update(resp.Header)
+
+		if retryable(resp.StatusCode) && attempt < r.maxRetries {
+			resp.Body.Close()
+			delay := backoff(r.backoffBase, attempt, resp.Header.Get("Retry-After"))
+			log.Printf("Reddit returned %d for %s, retrying in %s", resp.StatusCode, path, delay.Round(time.Millisecond))
+			r.limiter.Pause(delay)
+			continue
+		}
 
-	req, err := fhttp.NewRequest("GET", url, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode == fhttp.StatusUnauthorized && r.token != nil {
+			// The token was revoked or expired early, fetch a new one next time.
+			r.token.Invalidate()
+		}
+
+		if resp.StatusCode >= 400 {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("reddit error %d: %s", resp.StatusCode, body)
+		}
+
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+func (r *RedditClient) do(ctx context.Context, reqURL string) (*fhttp.Response, error) {
+	req, err := fhttp.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", r.userAgent)
 
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	if r.token != nil {
+		token, err := r.token.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return r.httpClient.Do(req)
+}
+
+// FetchPosts returns up to opts.Limit posts, following the listing cursors
+// across pages. Paging goes back in time unless opts.Before is set.
+func (r *RedditClient) FetchPosts(ctx context.Context, subreddit string, opts ListingOptions) ([]*Post, error) {
+	var posts []*Post
+	for len(posts) < opts.Limit {
+		page := opts
+		page.Limit = opts.Limit - len(posts)
+
+		listing, err := r.FetchListing(ctx, subreddit, page)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, listing.Posts...)
+
+		if len(listing.Posts) == 0 {
+			break
+		}
+		if opts.Before != "" {
+			if listing.Before == "" {
+				break
+			}
+			opts.Before = listing.Before
+		} else {
+			if listing.After == "" {
+				break
+			}
+			opts.After = listing.After
+		}
+	}
+
+	return posts, nil
+}
+
+// FetchListing fetches a single page of a subreddit listing. Search results
+// are restricted to the subreddit.
+func (r *RedditClient) FetchListing(ctx context.Context, subreddit string, opts ListingOptions) (*Listing, error) {
+	limit := opts.Limit
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("reddit error %d: %s", resp.StatusCode, body)
+	sort := opts.Sort
+	if sort == "" {
+		sort = "new"
 	}
 
-	var listing redditListingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if sort == "search" {
+		query.Set("q", opts.Query)
+		query.Set("restrict_sr", "1")
+		query.Set("sort", "new")
+	}
+	if opts.Time != "" && (sort == "top" || sort == "search") {
+		query.Set("t", opts.Time)
+	}
+	if opts.After != "" {
+		query.Set("after", opts.After)
+	}
+	if opts.Before != "" {
+		query.Set("before", opts.Before)
+	}
+
+	var data redditListingResponse
+	if err := r.get(ctx, fmt.Sprintf("/r/%s/%s.json", subreddit, sort), query, &data); err != nil {
 		return nil, err
 	}
 
-	var posts []*Post
-	for _, c := range listing.Data.Children {
+	listing := &Listing{
+		After:  data.Data.After,
+		Before: data.Data.Before,
+	}
+	for _, c := range data.Data.Children {
 		p := c.Data
-		posts = append(posts, &Post{
+		post := &Post{
 			ID:        p.ID,
 			Title:     p.Title,
 			Content:   p.Selftext,
@@ -108,65 +377,153 @@ func (r *RedditClient) FetchPosts(ctx context.Context, subreddit string, limit i
 			URL:       "https://reddit.com" + p.Permalink,
 			Score:     p.Score,
 			CreatedAt: time.Unix(int64(p.CreatedUTC), 0),
-		})
+
+			NumComments:          p.NumComments,
+			UpvoteRatio:          p.UpvoteRatio,
+			Flair:                p.LinkFlairText,
+			NSFW:                 p.Over18,
+			IsSelf:               p.IsSelf,
+			Stickied:             p.Stickied,
+			EditedAt:             p.editedAt(),
+			SubredditSubscribers: p.SubredditSubscribers,
+		}
+		if !p.IsSelf {
+			post.ExternalURL = p.URL
+		}
+		listing.Posts = append(listing.Posts, post)
 	}
 
-	return posts, nil
+	return listing, nil
 }
 
-// Fetch post comments using public JSON
-func (r *RedditClient) FetchComments(ctx context.Context, subreddit, postID string) ([]*Post, error) {
-	url := fmt.Sprintf(
-		"https://www.reddit.com/r/%s/comments/%s.json",
-		subreddit, postID,
-	)
+// FetchComments returns the comment tree of a post in depth-first order.
+// Collapsed "more" stubs are expanded through /api/morechildren; those
+// comments come after the rest of the tree, so use ParentID and Depth to
+// rebuild the structure.
+func (r *RedditClient) FetchComments(ctx context.Context, subreddit, postID string, opts CommentOptions) ([]*Post, error) {
+	query := url.Values{}
+	if opts.MaxDepth > 0 {
+		query.Set("depth", strconv.Itoa(opts.MaxDepth))
+	}
+	if opts.MaxComments > 0 {
+		query.Set("limit", strconv.Itoa(opts.MaxComments))
+	}
 
-	req, err := fhttp.NewRequest("GET", url, nil)
-	if err != nil {
+	var data []redditListingResponse
+	if err := r.get(ctx, fmt.Sprintf("/r/%s/comments/%s.json", subreddit, postID), query, &data); err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", r.userAgent)
 
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	if len(data) < 2 {
+		return []*Post{}, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("reddit error %d: %s", resp.StatusCode, body)
+	tree := &commentTree{subreddit: subreddit, opts: opts}
+	tree.walk(data[1].Data.Children)
+
+	for len(tree.more) > 0 && !tree.full() {
+		batch := tree.more
+		if len(batch) > maxMoreChildren {
+			batch = batch[:maxMoreChildren]
+		}
+		tree.more = tree.more[len(batch):]
+
+		things, err := r.fetchMoreChildren(ctx, postID, batch)
+		if err != nil {
+			return nil, err
+		}
+		tree.walk(things)
 	}
 
-	var data []redditListingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	return tree.comments, nil
+}
+
+func (r *RedditClient) fetchMoreChildren(ctx context.Context, postID string, children []string) ([]redditThing, error) {
+	query := url.Values{}
+	query.Set("api_type", "json")
+	query.Set("link_id", "t3_"+postID)
+	query.Set("children", strings.Join(children, ","))
+	query.Set("limit_children", "false")
+
+	var resp redditMoreChildrenResponse
+	if err := r.get(ctx, "/api/morechildren.json", query, &resp); err != nil {
 		return nil, err
 	}
-
-	if len(data) < 2 {
-		return []*Post{}, nil
+	if len(resp.JSON.Errors) > 0 {
+		return nil, fmt.Errorf("reddit morechildren error: %v", resp.JSON.Errors)
 	}
 
-	commentListing := data[1]
+	return resp.JSON.Data.Things, nil
+}
+
+// commentTree flattens comment listings and collects the IDs of collapsed
+// comments that still need expanding.
+type commentTree struct {
+	subreddit string
+	opts      CommentOptions
+	comments  []*Post
+	more      []string
+}
 
-	var comments []*Post
-	for _, c := range commentListing.Data.Children {
-		p := c.Data
-		if p.Body == "" || p.Body == "[deleted]" || p.Body == "[removed]" {
+func (t *commentTree) full() bool {
+	return t.opts.MaxComments > 0 && len(t.comments) >= t.opts.MaxComments
+}
+
+func (t *commentTree) walk(things []redditThing) {
+	for _, thing := range things {
+		if t.full() {
+			return
+		}
+
+		p := thing.Data
+		if t.opts.MaxDepth > 0 && p.Depth >= t.opts.MaxDepth {
 			continue
 		}
 
-		comments = append(comments, &Post{
-			ID:        p.ID,
-			Title:     "",
-			Content:   p.Body,
-			Author:    p.Author,
-			Subreddit: subreddit,
-			URL:       "https://reddit.com" + p.Permalink,
-			Score:     0,
-			CreatedAt: time.Unix(int64(p.CreatedUTC), 0),
-		})
+		switch thing.Kind {
+		case "more":
+			// An empty stub is a "continue this thread" link, not expandable here
+			t.more = append(t.more, p.Children...)
+		case "t1":
+			// Keep walking removed comments, their replies may still be there
+			if p.Body != "" && p.Body != "[deleted]" && p.Body != "[removed]" {
+				t.comments = append(t.comments, &Post{
+					ID:        p.ID,
+					Title:     "",
+					Content:   p.Body,
+					Author:    p.Author,
+					Subreddit: t.subreddit,
+					URL:       "https://reddit.com" + p.Permalink,
+					Score:     p.Score,
+					CreatedAt: time.Unix(int64(p.CreatedUTC), 0),
+					ParentID:  p.ParentID,
+					Depth:     p.Depth,
+				})
+			}
+			t.walk(p.replies())
+		}
 	}
+}
+
+// editedAt decodes the edited field, which is false for posts that were
+// never edited and the edit timestamp otherwise.
+func (p redditPost) editedAt() time.Time {
+	var ts float64
+	if err := json.Unmarshal(p.Edited, &ts); err != nil || ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(ts), 0)
+}
 
-	return comments, nil
+// replies decodes the nested replies listing, which Reddit sends as an
+// empty string when a comment has none.
+func (p redditPost) replies() []redditThing {
+	if len(p.Replies) == 0 || p.Replies[0] != '{' {
+		return nil
+	}
+	var listing redditListingResponse
+	if err := json.Unmarshal(p.Replies, &listing); err != nil {
+		return nil
+	}
+	return listing.Data.Children
 }
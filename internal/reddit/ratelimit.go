@@ -0,0 +1,68 @@
+package reddit
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	fhttp "github.com/bogdanfinn/fhttp"
+	"github.com/letieu/idea-extractor/internal/ratelimit"
+)
+
+const (
+	defaultBackoffBase = 2 * time.Second
+	backoffMax         = 2 * time.Minute
+)
+
+// rateLimiter spaces out the requests of a RedditClient. It enforces the
+// configured minimum interval and slows down further based on the
+// x-ratelimit-* headers Reddit sends with every response.
+type rateLimiter struct {
+	*ratelimit.Limiter
+}
+
+func newRateLimiter(minInterval time.Duration) *rateLimiter {
+	return &rateLimiter{Limiter: ratelimit.New(minInterval)}
+}
+
+// update adapts the pace to the remaining request budget. Reddit reports
+// how many requests are left and how many seconds until the window resets;
+// the remaining requests are spread evenly over that time.
+func (l *rateLimiter) update(h fhttp.Header) {
+	remaining, err := strconv.ParseFloat(h.Get("x-ratelimit-remaining"), 64)
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseFloat(h.Get("x-ratelimit-reset"), 64)
+	if err != nil {
+		return
+	}
+
+	window := time.Duration(reset * float64(time.Second))
+	if remaining < 1 {
+		l.Pause(window)
+		return
+	}
+	l.Pause(time.Duration(float64(window) / remaining))
+}
+
+func retryable(status int) bool {
+	return status == fhttp.StatusTooManyRequests || status >= 500
+}
+
+// backoff returns the delay before retry attempt+1: exponential from base
+// with jitter, and never shorter than what a Retry-After header asks for.
+func backoff(base time.Duration, attempt int, retryAfter string) time.Duration {
+	delay := base << attempt
+	if delay > backoffMax || delay <= 0 {
+		delay = backoffMax
+	}
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if secs, err := strconv.Atoi(retryAfter); err == nil {
+		if d := time.Duration(secs) * time.Second; d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
@@ -0,0 +1,133 @@
+package reddit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const listingJSON = `{"data":{"children":[{"kind":"t3","data":{"id":"abc","title":"Hello","permalink":"/r/golang/comments/abc/hello/","is_self":true}}],"after":""}}`
+
+func newAnonymousClient(t *testing.T, baseURL string, opts Options) *RedditClient {
+	t.Helper()
+	opts.Anonymous = true
+	opts.BaseURL = baseURL
+	if opts.BackoffBase == 0 {
+		opts.BackoffBase = time.Millisecond
+	}
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestGetRetriesThrottledAndServerErrors(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+				return
+			}
+			w.Write([]byte(listingJSON))
+		}))
+
+		client := newAnonymousClient(t, srv.URL, Options{})
+		listing, err := client.FetchListing(context.Background(), "golang", ListingOptions{Limit: 1})
+		srv.Close()
+		if err != nil {
+			t.Fatalf("status %d: FetchListing: %v", status, err)
+		}
+		if len(listing.Posts) != 1 || listing.Posts[0].ID != "abc" {
+			t.Fatalf("status %d: unexpected posts %+v", status, listing.Posts)
+		}
+		if got := calls.Load(); got != 3 {
+			t.Fatalf("status %d: got %d requests, want 3", status, got)
+		}
+	}
+}
+
+func TestGetGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := newAnonymousClient(t, srv.URL, Options{MaxRetries: 2})
+	if _, err := client.FetchListing(context.Background(), "golang", ListingOptions{}); err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("got %d requests, want 3", got)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	client := newAnonymousClient(t, srv.URL, Options{})
+	if _, err := client.FetchListing(context.Background(), "golang", ListingOptions{}); err == nil {
+		t.Fatal("expected an error for a 403")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("got %d requests, want 1", got)
+	}
+}
+
+func TestRateLimitHeadersPaceRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-remaining", "1")
+		w.Header().Set("x-ratelimit-reset", "0.2")
+		w.Write([]byte(listingJSON))
+	}))
+	defer srv.Close()
+
+	client := newAnonymousClient(t, srv.URL, Options{})
+	ctx := context.Background()
+	if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err != nil {
+		t.Fatalf("FetchListing: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.FetchListing(ctx, "golang", ListingOptions{}); err != nil {
+		t.Fatalf("FetchListing: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("second request came after %s, want the 200ms window to be spread", elapsed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 4; attempt++ {
+		max := base << attempt
+		for i := 0; i < 20; i++ {
+			d := backoff(base, attempt, "")
+			if d < max/2 || d > max {
+				t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, d, max/2, max)
+			}
+		}
+	}
+
+	if d := backoff(base, 30, ""); d > backoffMax {
+		t.Fatalf("delay %s exceeds the %s cap", d, backoffMax)
+	}
+	if d := backoff(time.Millisecond, 0, "3"); d < 3*time.Second {
+		t.Fatalf("delay %s is shorter than Retry-After", d)
+	}
+	if d := backoff(time.Millisecond, 0, "soon"); d > time.Millisecond {
+		t.Fatalf("invalid Retry-After changed the delay to %s", d)
+	}
+}
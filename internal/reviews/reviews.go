@@ -0,0 +1,210 @@
+package reviews
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Review is one app store or review-site review.
+type Review struct {
+	ID      string // From the dump, or a hash of product, date and text
+	Product string
+	Rating  float64 // 0 when the dump has no rating
+	Title   string
+	Text    string
+	Author  string
+	Date    time.Time
+}
+
+// Column names accepted for each field, after columnKey. Dumps from
+// scrapers and review sites rarely agree on naming.
+var columns = map[string][]string{
+	"id":      {"id", "review_id", "reviewid"},
+	"product": {"product", "product_name", "productname", "app", "app_name", "appname"},
+	"rating":  {"rating", "stars", "score", "star_rating"},
+	"title":   {"title", "summary", "headline"},
+	"text":    {"text", "review", "body", "content", "comment", "review_text"},
+	"author":  {"author", "user", "user_name", "username", "reviewer"},
+	"date":    {"date", "created_at", "reviewed_at", "published", "time", "at"},
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+	"01/02/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// Load reads a CSV or JSON review dump, chosen by file extension. JSON
+// dumps are an array of objects or an object with a "reviews" array.
+// Reviews without product or text are dropped.
+func Load(path string) ([]*Review, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err = readCSV(f)
+	case ".json":
+		records, err = readJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported review dump %s, expected .csv or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse review dump %s: %w", path, err)
+	}
+
+	reviews := make([]*Review, 0, len(records))
+	for _, rec := range records {
+		r := &Review{
+			ID:      field(rec, "id"),
+			Product: field(rec, "product"),
+			Title:   field(rec, "title"),
+			Text:    field(rec, "text"),
+			Author:  field(rec, "author"),
+			Date:    parseDate(field(rec, "date")),
+		}
+		r.Rating, _ = strconv.ParseFloat(field(rec, "rating"), 64)
+		if r.Product == "" || r.Text == "" {
+			continue
+		}
+		if r.ID == "" {
+			sum := sha1.Sum([]byte(r.Product + "\x00" + field(rec, "date") + "\x00" + r.Text))
+			r.ID = hex.EncodeToString(sum[:8])
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, nil
+}
+
+// GroupByProduct groups reviews by product name, case-insensitively,
+// oldest review first. Products are returned in name order.
+func GroupByProduct(reviews []*Review) [][]*Review {
+	byProduct := map[string][]*Review{}
+	for _, r := range reviews {
+		key := strings.ToLower(r.Product)
+		byProduct[key] = append(byProduct[key], r)
+	}
+
+	keys := make([]string, 0, len(byProduct))
+	for k := range byProduct {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	groups := make([][]*Review, 0, len(keys))
+	for _, k := range keys {
+		group := byProduct[k]
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Date.Before(group[j].Date)
+		})
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		rec := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				rec[columnKey(name)] = row[i]
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []map[string]any
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var wrapped struct {
+			Reviews []map[string]any `json:"reviews"`
+		}
+		if json.Unmarshal(raw, &wrapped) != nil {
+			return nil, err
+		}
+		list = wrapped.Reviews
+	}
+
+	records := make([]map[string]string, 0, len(list))
+	for _, obj := range list {
+		rec := make(map[string]string, len(obj))
+		for k, v := range obj {
+			switch v := v.(type) {
+			case string:
+				rec[columnKey(k)] = v
+			case float64:
+				rec[columnKey(k)] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// columnKey normalizes "App Name" and "app-name" to "app_name".
+func columnKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+func field(rec map[string]string, name string) string {
+	for _, col := range columns[name] {
+		if v := strings.TrimSpace(rec[col]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseDate(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
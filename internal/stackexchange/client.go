@@ -0,0 +1,287 @@
+package stackexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+)
+
+const defaultBaseURL = "https://api.stackexchange.com/2.3"
+
+// Client reads questions and answers through the Stack Exchange API. The
+// API asks clients to pause after responses carrying a backoff field, the
+// client honors it before its next request.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	key        string
+
+	mu        sync.Mutex
+	notBefore time.Time
+}
+
+// Question is a question with its body converted to plain text.
+type Question struct {
+	ID          string
+	Title       string
+	Body        string
+	Author      string
+	URL         string
+	Tags        []string
+	Score       int
+	AnswerCount int
+	IsAnswered  bool
+	CreatedAt   time.Time
+}
+
+// Answer is an answer with its body converted to plain text.
+type Answer struct {
+	ID         string
+	Body       string
+	Author     string
+	URL        string
+	Score      int
+	IsAccepted bool
+	CreatedAt  time.Time
+}
+
+// QuestionOptions selects the questions to list.
+type QuestionOptions struct {
+	// Listing is new for the newest questions, unanswered for questions
+	// without an upvoted answer, or no_answers for those without any answer
+	Listing string
+	Tagged  []string // Questions must have all of these tags
+	Query   string   // Full-text search, optional
+	Limit   int
+}
+
+type owner struct {
+	DisplayName string `json:"display_name"`
+}
+
+type apiQuestion struct {
+	QuestionID   int64    `json:"question_id"`
+	Title        string   `json:"title"`
+	Body         string   `json:"body"`
+	Owner        owner    `json:"owner"`
+	Link         string   `json:"link"`
+	Tags         []string `json:"tags"`
+	Score        int      `json:"score"`
+	AnswerCount  int      `json:"answer_count"`
+	IsAnswered   bool     `json:"is_answered"`
+	CreationDate int64    `json:"creation_date"`
+}
+
+type apiAnswer struct {
+	AnswerID     int64  `json:"answer_id"`
+	QuestionID   int64  `json:"question_id"`
+	Body         string `json:"body"`
+	Owner        owner  `json:"owner"`
+	Score        int    `json:"score"`
+	IsAccepted   bool   `json:"is_accepted"`
+	CreationDate int64  `json:"creation_date"`
+}
+
+type wrapper struct {
+	Items        json.RawMessage `json:"items"`
+	Backoff      int             `json:"backoff"`
+	ErrorID      int             `json:"error_id"`
+	ErrorName    string          `json:"error_name"`
+	ErrorMessage string          `json:"error_message"`
+}
+
+// NewClient creates a Stack Exchange client. An empty baseURL uses
+// api.stackexchange.com, the key is optional and raises the daily quota.
+func NewClient(baseURL, key string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		key:        key,
+	}
+}
+
+// ListQuestions returns the newest questions of site, e.g. softwarerecs,
+// matching opts.
+func (c *Client) ListQuestions(ctx context.Context, site string, opts QuestionOptions) ([]*Question, error) {
+	query := url.Values{}
+	query.Set("site", site)
+	query.Set("order", "desc")
+	query.Set("sort", "creation")
+	query.Set("filter", "withbody")
+	query.Set("pagesize", strconv.Itoa(pageSize(opts.Limit)))
+	if len(opts.Tagged) > 0 {
+		query.Set("tagged", strings.Join(opts.Tagged, ";"))
+	}
+
+	path := "/questions"
+	switch {
+	case opts.Query != "":
+		// Search has its own answer filters instead of listings
+		path = "/search/advanced"
+		query.Set("q", opts.Query)
+		switch opts.Listing {
+		case "unanswered":
+			query.Set("accepted", "False")
+		case "no_answers":
+			query.Set("answers", "0")
+		}
+	case opts.Listing == "unanswered":
+		path = "/questions/unanswered"
+	case opts.Listing == "no_answers":
+		path = "/questions/no-answers"
+	}
+
+	var resp []apiQuestion
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+
+	questions := make([]*Question, 0, len(resp))
+	for _, r := range resp {
+		questions = append(questions, &Question{
+			ID:          strconv.FormatInt(r.QuestionID, 10),
+			Title:       html.UnescapeString(r.Title),
+			Body:        htmltext.ToText(r.Body),
+			Author:      html.UnescapeString(r.Owner.DisplayName),
+			URL:         r.Link,
+			Tags:        r.Tags,
+			Score:       r.Score,
+			AnswerCount: r.AnswerCount,
+			IsAnswered:  r.IsAnswered,
+			CreatedAt:   time.Unix(r.CreationDate, 0),
+		})
+	}
+	return questions, nil
+}
+
+// ListAnswers returns the answers to a question, highest voted first.
+func (c *Client) ListAnswers(ctx context.Context, site, questionID string, limit int) ([]*Answer, error) {
+	query := url.Values{}
+	query.Set("site", site)
+	query.Set("order", "desc")
+	query.Set("sort", "votes")
+	query.Set("filter", "withbody")
+	query.Set("pagesize", strconv.Itoa(pageSize(limit)))
+
+	var resp []apiAnswer
+	if err := c.get(ctx, "/questions/"+questionID+"/answers", query, &resp); err != nil {
+		return nil, err
+	}
+
+	answers := make([]*Answer, 0, len(resp))
+	for _, r := range resp {
+		id := strconv.FormatInt(r.AnswerID, 10)
+		answers = append(answers, &Answer{
+			ID:         id,
+			Body:       htmltext.ToText(r.Body),
+			Author:     html.UnescapeString(r.Owner.DisplayName),
+			URL:        fmt.Sprintf("https://%s/a/%s", siteHost(site), id),
+			Score:      r.Score,
+			IsAccepted: r.IsAccepted,
+			CreatedAt:  time.Unix(r.CreationDate, 0),
+		})
+	}
+	return answers, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+
+	if c.key != "" {
+		query.Set("key", c.key)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var w wrapper
+	if err := json.Unmarshal(body, &w); err != nil {
+		return fmt.Errorf("stackexchange error %d: %s", resp.StatusCode, body)
+	}
+	if w.Backoff > 0 {
+		c.mu.Lock()
+		c.notBefore = time.Now().Add(time.Duration(w.Backoff) * time.Second)
+		c.mu.Unlock()
+	}
+	if resp.StatusCode != http.StatusOK || w.ErrorID != 0 {
+		return fmt.Errorf("stackexchange error %d: %s: %s", resp.StatusCode, w.ErrorName, w.ErrorMessage)
+	}
+
+	return json.Unmarshal(w.Items, out)
+}
+
+// wait blocks until the backoff requested by the API has passed.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	d := time.Until(c.notBefore)
+	c.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var siteHosts = map[string]string{
+	"stackoverflow": "stackoverflow.com",
+	"serverfault":   "serverfault.com",
+	"superuser":     "superuser.com",
+	"askubuntu":     "askubuntu.com",
+	"mathoverflow":  "mathoverflow.net",
+}
+
+// siteHost turns an API site parameter into its domain, e.g. softwarerecs
+// into softwarerecs.stackexchange.com. Sites like stackoverflow already
+// have their own domain.
+func siteHost(site string) string {
+	if strings.Contains(site, ".") {
+		return site
+	}
+	if host, ok := siteHosts[site]; ok {
+		return host
+	}
+	return site + ".stackexchange.com"
+}
+
+// Stack Exchange pages hold at most 100 items.
+func pageSize(limit int) int {
+	if limit <= 0 || limit > 100 {
+		return 100
+	}
+	return limit
+}
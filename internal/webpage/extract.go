@@ -0,0 +1,419 @@
+package webpage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/letieu/idea-extractor/internal/htmltext"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Content extraction follows the Readability approach: paragraphs score
+// their parent and grandparent by length and commas, class and id names
+// push the score up or down, and the best scoring element and its related
+// siblings are kept.
+var (
+	unlikely = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|menu|modal|nav|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|widget|\bad-|ads\b`)
+	maybe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|post|entry|story`)
+	positive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negative = regexp.MustCompile(`(?i)-ad-|hidden|^hid$|\bhid\b|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// Elements that never hold article text.
+var removed = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Svg: true, atom.Button: true, atom.Select: true, atom.Object: true,
+	atom.Embed: true, atom.Template: true,
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
+// Extract parses an HTML document and returns its metadata and main text.
+// base resolves relative links and canonical URLs.
+func Extract(r io.Reader, base *url.URL) (*Page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	page := &Page{URL: base.String()}
+	readMetadata(doc, base, page)
+
+	clean(doc)
+	resolveLinks(doc, base)
+	page.Text = articleText(doc)
+	return page, nil
+}
+
+// readMetadata fills title, author, site name, date and canonical URL from
+// meta tags, JSON-LD and, as a last resort, the markup.
+func readMetadata(doc *html.Node, base *url.URL, page *Page) {
+	meta := map[string]string{}
+	var title, canonical, timeTag string
+	var h1s []string
+	var ld []any
+
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Meta:
+			key := strings.ToLower(firstNonEmpty(attr(n, "property"), attr(n, "name"), attr(n, "itemprop")))
+			if key != "" && meta[key] == "" {
+				meta[key] = strings.TrimSpace(attr(n, "content"))
+			}
+		case atom.Title:
+			if title == "" {
+				title = textContent(n)
+			}
+		case atom.H1:
+			h1s = append(h1s, textContent(n))
+		case atom.Link:
+			if strings.EqualFold(attr(n, "rel"), "canonical") {
+				canonical = attr(n, "href")
+			}
+		case atom.Time:
+			if timeTag == "" {
+				timeTag = attr(n, "datetime")
+			}
+		case atom.Script:
+			if strings.Contains(attr(n, "type"), "ld+json") {
+				var v any
+				if json.Unmarshal([]byte(textContent(n)), &v) == nil {
+					ld = append(ld, v)
+				}
+			}
+		}
+		return true
+	})
+
+	var ldTitle, ldAuthor, ldDate string
+	for _, v := range ld {
+		ldFields(v, &ldTitle, &ldAuthor, &ldDate)
+	}
+
+	var h1 string
+	if len(h1s) == 1 {
+		h1 = h1s[0]
+	}
+	page.Title = firstNonEmpty(meta["og:title"], meta["twitter:title"], ldTitle, h1, title)
+	page.SiteName = firstNonEmpty(meta["og:site_name"], meta["application-name"])
+
+	author := firstNonEmpty(meta["author"], meta["article:author"], ldAuthor, meta["twitter:creator"], meta["dc.creator"])
+	if !strings.HasPrefix(author, "http") {
+		page.Author = author
+	}
+
+	page.Published = parseDate(firstNonEmpty(
+		meta["article:published_time"], meta["datepublished"], ldDate,
+		meta["date"], meta["pubdate"], meta["publishdate"], meta["dc.date.issued"], meta["dc.date"],
+		timeTag,
+	))
+
+	if u, err := base.Parse(firstNonEmpty(canonical, meta["og:url"])); err == nil && u.Host == base.Host && u.String() != "" {
+		page.URL = u.String()
+	}
+}
+
+// ldFields looks for the headline, author and publish date of the first
+// article-like object in a JSON-LD value.
+func ldFields(v any, title, author, date *string) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			ldFields(item, title, author, date)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			ldFields(graph, title, author, date)
+		}
+		if s, ok := v["headline"].(string); ok && *title == "" {
+			*title = s
+		}
+		if s, ok := v["datePublished"].(string); ok && *date == "" {
+			*date = s
+		}
+		if *author == "" {
+			*author = ldName(v["author"])
+		}
+	}
+}
+
+func ldName(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		name, _ := v["name"].(string)
+		return name
+	case []any:
+		var names []string
+		for _, item := range v {
+			if name := ldName(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// clean drops elements that never hold article text, hidden ones and those
+// whose class or id name them as page furniture.
+func clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || c.Type == html.ElementNode && isBoilerplate(c) {
+			n.RemoveChild(c)
+		} else {
+			clean(c)
+		}
+		c = next
+	}
+}
+
+func isBoilerplate(n *html.Node) bool {
+	if removed[n.DataAtom] {
+		return true
+	}
+	if _, hidden := attrOK(n, "hidden"); hidden || strings.EqualFold(attr(n, "aria-hidden"), "true") {
+		return true
+	}
+	if strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+		return true
+	}
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article || n.DataAtom == atom.Main || n.DataAtom == atom.A {
+		return false
+	}
+	names := attr(n, "class") + " " + attr(n, "id") + " " + attr(n, "role")
+	return unlikely.MatchString(names) && !maybe.MatchString(names)
+}
+
+func resolveLinks(doc *html.Node, base *url.URL) {
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.A {
+			return true
+		}
+		for i, a := range n.Attr {
+			if a.Key == "href" {
+				if u, err := base.Parse(a.Val); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+					n.Attr[i].Val = u.String()
+				}
+			}
+		}
+		return true
+	})
+}
+
+// articleText picks the best scoring content element and returns the text
+// of it and its related siblings, or of the whole body when no paragraph
+// qualifies.
+func articleText(doc *html.Node) string {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+		text := textContent(n)
+		if len(text) < 25 {
+			return false
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		for level, ancestor := 0, n.Parent; level < 2 && ancestor != nil && ancestor.Type == html.ElementNode; level, ancestor = level+1, ancestor.Parent {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+			if level == 0 {
+				scores[ancestor] += score
+			} else {
+				scores[ancestor] += score / 2
+			}
+		}
+		return false
+	})
+
+	var top *html.Node
+	for _, c := range candidates {
+		scores[c] *= 1 - linkDensity(c)
+		if top == nil || scores[c] > scores[top] {
+			top = c
+		}
+	}
+
+	if top == nil {
+		return htmltext.ToText(render(findBody(doc)))
+	}
+
+	// Siblings holding more of the article, e.g. a split into several
+	// divs, are kept next to the top candidate
+	if top.Parent == nil {
+		return htmltext.ToText(render(top))
+	}
+	threshold := math.Max(10, scores[top]*0.2)
+	var b strings.Builder
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type != html.ElementNode {
+			continue
+		}
+		keep := s == top
+		if score, ok := scores[s]; ok && score >= threshold {
+			keep = true
+		}
+		if s.DataAtom == atom.P {
+			text := textContent(s)
+			density := linkDensity(s)
+			if len(text) > 80 && density < 0.25 || len(text) > 0 && density == 0 && strings.Contains(text, ". ") {
+				keep = true
+			}
+		}
+		if keep {
+			b.WriteString(render(s))
+		}
+	}
+	return htmltext.ToText(b.String())
+}
+
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score = 10
+	case atom.Div:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negative.MatchString(name) {
+			score -= 25
+		}
+		if positive.MatchString(name) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of the text of n that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(textContent(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += len(textContent(c))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+func findBody(doc *html.Node) *html.Node {
+	body := doc
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Body {
+			body = n
+			return false
+		}
+		return true
+	})
+	return body
+}
+
+// walk calls fn for every element below n in document order, descending
+// into an element only when fn returns true.
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !fn(c) {
+			continue
+		}
+		walk(c, fn)
+	}
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func render(n *html.Node) string {
+	var buf bytes.Buffer
+	html.Render(&buf, n)
+	return buf.String()
+}
+
+func attr(n *html.Node, name string) string {
+	v, _ := attrOK(n, name)
+	return v
+}
+
+func attrOK(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package webpage
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func extractFile(t *testing.T, path, pageURL string) *Page {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	base, _ := url.Parse(pageURL)
+	page, err := Extract(f, base)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	return page
+}
+
+func TestExtractArticle(t *testing.T) {
+	page := extractFile(t, "testdata/article.html", "https://notes.example.com/posts/invoicing?ref=hn")
+
+	if page.Title != "Why I stopped invoicing by hand" || page.Author != "Alice Martin" || page.SiteName != "Maker Notes" {
+		t.Fatalf("unexpected metadata %+v", page)
+	}
+	if !page.Published.Equal(time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Published = %s", page.Published)
+	}
+	if page.URL != "https://notes.example.com/posts/invoicing?utm_source=feed" {
+		t.Fatalf("URL = %q, want the canonical URL", page.URL)
+	}
+
+	for _, want := range []string{"Every month I spent a whole afternoon", "three invoicing tools", "happily pay for a product"} {
+		if !strings.Contains(page.Text, want) {
+			t.Errorf("Text misses %q:\n%s", want, page.Text)
+		}
+	}
+	// Navigation, banners, hidden elements, scripts, comments and footers
+	// are not article text
+	for _, boilerplate := range []string{"Archive", "cookies", "Hidden promotion", "newsletter", "editor", "Related posts", "Great post", "Copyright"} {
+		if strings.Contains(page.Text, boilerplate) {
+			t.Errorf("Text keeps %q:\n%s", boilerplate, page.Text)
+		}
+	}
+}
+
+func TestExtractShortPage(t *testing.T) {
+	page := extractFile(t, "testdata/short.html", "https://app.example.com/login")
+
+	// Without a qualifying paragraph the body text is kept as is
+	if page.Text != "Please sign in." {
+		t.Fatalf("Text = %q", page.Text)
+	}
+	if page.Title != "Sign in" || page.URL != "https://app.example.com/login" {
+		t.Fatalf("unexpected metadata %+v", page)
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://Example.com/post#comments", "https://example.com/post"},
+		{" https://example.com/post?utm_source=x&utm_medium=y&id=3 ", "https://example.com/post?id=3"},
+		{"https://example.com/post?ref=hn&fbclid=1&gclid=2", "https://example.com/post"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.in); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package webpage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Client downloads web pages and extracts their main content.
+type Client struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+// Page is the readable part of a web page.
+type Page struct {
+	URL       string // Canonical URL when the page declares one on the same host
+	Title     string
+	Author    string
+	SiteName  string
+	Published time.Time
+	Text      string
+}
+
+// Pages larger than this are cut, articles are far smaller.
+const maxPageSize = 5 << 20
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "Mozilla/5.0 (compatible; idea-extractor/1.0)",
+	}
+}
+
+// Fetch downloads the page at pageURL and extracts its article.
+func (c *Client) Fetch(ctx context.Context, pageURL string) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("page error %d: %s", resp.StatusCode, body)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("not an html page: %s", contentType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageSize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	// Redirects may have moved us, relative links resolve against the final URL
+	return Extract(body, resp.Request.URL)
+}
+
+// NormalizeURL drops the fragment and tracking parameters so the same
+// article shared from different places gets the same key.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+
+	q := u.Query()
+	for key := range q {
+		if strings.HasPrefix(key, "utm_") || key == "ref" || key == "fbclid" || key == "gclid" {
+			q.Del(key)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}